@@ -0,0 +1,5 @@
+// Package extension provides the meta APIs which are not defined in the Vald meta service.
+// extension.pb.go is generated from apis/proto/extension.proto, the include paths follow the PROTO_PATHS of Vald.
+package extension
+
+//go:generate protoc -I ../../proto -I $GOPATH/src/github.com/vdaas/vald/apis/proto/payload -I $GOPATH/src/github.com/envoyproxy/protoc-gen-validate --gogofast_out=plugins=grpc,paths=source_relative:. extension.proto
@@ -0,0 +1,4386 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: extension.proto
+
+package extension
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	payload "github.com/vdaas/vald/apis/grpc/payload"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type CompareAndSetRequest struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Expected             string   `protobuf:"bytes,2,opt,name=expected,proto3" json:"expected,omitempty"`
+	Val                  string   `protobuf:"bytes,3,opt,name=val,proto3" json:"val,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompareAndSetRequest) Reset()         { *m = CompareAndSetRequest{} }
+func (m *CompareAndSetRequest) String() string { return proto.CompactTextString(m) }
+func (*CompareAndSetRequest) ProtoMessage()    {}
+func (*CompareAndSetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{0}
+}
+func (m *CompareAndSetRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CompareAndSetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CompareAndSetRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CompareAndSetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompareAndSetRequest.Merge(m, src)
+}
+func (m *CompareAndSetRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *CompareAndSetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompareAndSetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompareAndSetRequest proto.InternalMessageInfo
+
+func (m *CompareAndSetRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *CompareAndSetRequest) GetExpected() string {
+	if m != nil {
+		return m.Expected
+	}
+	return ""
+}
+
+func (m *CompareAndSetRequest) GetVal() string {
+	if m != nil {
+		return m.Val
+	}
+	return ""
+}
+
+type IndexQueryRequest struct {
+	// field is the dot separated path of an indexed field.
+	Field                string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IndexQueryRequest) Reset()         { *m = IndexQueryRequest{} }
+func (m *IndexQueryRequest) String() string { return proto.CompactTextString(m) }
+func (*IndexQueryRequest) ProtoMessage()    {}
+func (*IndexQueryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{1}
+}
+func (m *IndexQueryRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *IndexQueryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_IndexQueryRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *IndexQueryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IndexQueryRequest.Merge(m, src)
+}
+func (m *IndexQueryRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *IndexQueryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_IndexQueryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IndexQueryRequest proto.InternalMessageInfo
+
+func (m *IndexQueryRequest) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *IndexQueryRequest) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type NamespaceRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NamespaceRequest) Reset()         { *m = NamespaceRequest{} }
+func (m *NamespaceRequest) String() string { return proto.CompactTextString(m) }
+func (*NamespaceRequest) ProtoMessage()    {}
+func (*NamespaceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{2}
+}
+func (m *NamespaceRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NamespaceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_NamespaceRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *NamespaceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NamespaceRequest.Merge(m, src)
+}
+func (m *NamespaceRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *NamespaceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NamespaceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NamespaceRequest proto.InternalMessageInfo
+
+func (m *NamespaceRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type NamespaceStats struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Count                int64    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Quota                int64    `protobuf:"varint,3,opt,name=quota,proto3" json:"quota,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NamespaceStats) Reset()         { *m = NamespaceStats{} }
+func (m *NamespaceStats) String() string { return proto.CompactTextString(m) }
+func (*NamespaceStats) ProtoMessage()    {}
+func (*NamespaceStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{3}
+}
+func (m *NamespaceStats) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NamespaceStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_NamespaceStats.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *NamespaceStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NamespaceStats.Merge(m, src)
+}
+func (m *NamespaceStats) XXX_Size() int {
+	return m.Size()
+}
+func (m *NamespaceStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_NamespaceStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NamespaceStats proto.InternalMessageInfo
+
+func (m *NamespaceStats) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *NamespaceStats) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *NamespaceStats) GetQuota() int64 {
+	if m != nil {
+		return m.Quota
+	}
+	return 0
+}
+
+type MetaVersion struct {
+	Version uint64 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Val     string `protobuf:"bytes,2,opt,name=val,proto3" json:"val,omitempty"`
+	// timestamp is when the version was set in unix nanoseconds.
+	Timestamp            int64    `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MetaVersion) Reset()         { *m = MetaVersion{} }
+func (m *MetaVersion) String() string { return proto.CompactTextString(m) }
+func (*MetaVersion) ProtoMessage()    {}
+func (*MetaVersion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{4}
+}
+func (m *MetaVersion) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetaVersion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetaVersion.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetaVersion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetaVersion.Merge(m, src)
+}
+func (m *MetaVersion) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetaVersion) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetaVersion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetaVersion proto.InternalMessageInfo
+
+func (m *MetaVersion) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *MetaVersion) GetVal() string {
+	if m != nil {
+		return m.Val
+	}
+	return ""
+}
+
+func (m *MetaVersion) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type MetaHistory struct {
+	Key                  string         `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Versions             []*MetaVersion `protobuf:"bytes,2,rep,name=versions,proto3" json:"versions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *MetaHistory) Reset()         { *m = MetaHistory{} }
+func (m *MetaHistory) String() string { return proto.CompactTextString(m) }
+func (*MetaHistory) ProtoMessage()    {}
+func (*MetaHistory) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{5}
+}
+func (m *MetaHistory) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetaHistory) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetaHistory.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetaHistory) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetaHistory.Merge(m, src)
+}
+func (m *MetaHistory) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetaHistory) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetaHistory.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetaHistory proto.InternalMessageInfo
+
+func (m *MetaHistory) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *MetaHistory) GetVersions() []*MetaVersion {
+	if m != nil {
+		return m.Versions
+	}
+	return nil
+}
+
+type RollbackRequest struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Version              uint64   `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RollbackRequest) Reset()         { *m = RollbackRequest{} }
+func (m *RollbackRequest) String() string { return proto.CompactTextString(m) }
+func (*RollbackRequest) ProtoMessage()    {}
+func (*RollbackRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{6}
+}
+func (m *RollbackRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RollbackRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RollbackRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RollbackRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RollbackRequest.Merge(m, src)
+}
+func (m *RollbackRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *RollbackRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RollbackRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RollbackRequest proto.InternalMessageInfo
+
+func (m *RollbackRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *RollbackRequest) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type TransactionOperation struct {
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// val is set to the key unless delete is true.
+	Val    string `protobuf:"bytes,2,opt,name=val,proto3" json:"val,omitempty"`
+	Delete bool   `protobuf:"varint,3,opt,name=delete,proto3" json:"delete,omitempty"`
+	// if_absent fails the transaction when the key exists.
+	IfAbsent bool `protobuf:"varint,4,opt,name=if_absent,json=ifAbsent,proto3" json:"if_absent,omitempty"`
+	// expected fails the transaction unless the current value is it, when it is not empty.
+	Expected string `protobuf:"bytes,5,opt,name=expected,proto3" json:"expected,omitempty"`
+	// expected_version fails the transaction unless the entry has the version, when it is not 0.
+	ExpectedVersion      uint64   `protobuf:"varint,6,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransactionOperation) Reset()         { *m = TransactionOperation{} }
+func (m *TransactionOperation) String() string { return proto.CompactTextString(m) }
+func (*TransactionOperation) ProtoMessage()    {}
+func (*TransactionOperation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{7}
+}
+func (m *TransactionOperation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransactionOperation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransactionOperation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransactionOperation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionOperation.Merge(m, src)
+}
+func (m *TransactionOperation) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransactionOperation) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionOperation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionOperation proto.InternalMessageInfo
+
+func (m *TransactionOperation) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *TransactionOperation) GetVal() string {
+	if m != nil {
+		return m.Val
+	}
+	return ""
+}
+
+func (m *TransactionOperation) GetDelete() bool {
+	if m != nil {
+		return m.Delete
+	}
+	return false
+}
+
+func (m *TransactionOperation) GetIfAbsent() bool {
+	if m != nil {
+		return m.IfAbsent
+	}
+	return false
+}
+
+func (m *TransactionOperation) GetExpected() string {
+	if m != nil {
+		return m.Expected
+	}
+	return ""
+}
+
+func (m *TransactionOperation) GetExpectedVersion() uint64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+type TransactionRequest struct {
+	Operations           []*TransactionOperation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *TransactionRequest) Reset()         { *m = TransactionRequest{} }
+func (m *TransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*TransactionRequest) ProtoMessage()    {}
+func (*TransactionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{8}
+}
+func (m *TransactionRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransactionRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransactionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionRequest.Merge(m, src)
+}
+func (m *TransactionRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransactionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionRequest proto.InternalMessageInfo
+
+func (m *TransactionRequest) GetOperations() []*TransactionOperation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+type TransactionResult struct {
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// code is the gRPC status code of the operation, ABORTED when another operation failed.
+	Code    uint32 `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// val is the deleted value of a delete.
+	Val string `protobuf:"bytes,4,opt,name=val,proto3" json:"val,omitempty"`
+	// version is the version of the entry after a put when the versioning is enabled.
+	Version              uint64   `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransactionResult) Reset()         { *m = TransactionResult{} }
+func (m *TransactionResult) String() string { return proto.CompactTextString(m) }
+func (*TransactionResult) ProtoMessage()    {}
+func (*TransactionResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{9}
+}
+func (m *TransactionResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransactionResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransactionResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransactionResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionResult.Merge(m, src)
+}
+func (m *TransactionResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransactionResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionResult proto.InternalMessageInfo
+
+func (m *TransactionResult) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *TransactionResult) GetCode() uint32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *TransactionResult) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *TransactionResult) GetVal() string {
+	if m != nil {
+		return m.Val
+	}
+	return ""
+}
+
+func (m *TransactionResult) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type TransactionResponse struct {
+	Committed            bool                 `protobuf:"varint,1,opt,name=committed,proto3" json:"committed,omitempty"`
+	Results              []*TransactionResult `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *TransactionResponse) Reset()         { *m = TransactionResponse{} }
+func (m *TransactionResponse) String() string { return proto.CompactTextString(m) }
+func (*TransactionResponse) ProtoMessage()    {}
+func (*TransactionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{10}
+}
+func (m *TransactionResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransactionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransactionResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransactionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionResponse.Merge(m, src)
+}
+func (m *TransactionResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransactionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionResponse proto.InternalMessageInfo
+
+func (m *TransactionResponse) GetCommitted() bool {
+	if m != nil {
+		return m.Committed
+	}
+	return false
+}
+
+func (m *TransactionResponse) GetResults() []*TransactionResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type TrashListRequest struct {
+	// limit is the maximum number of the entries, all the entries are returned when it is 0.
+	Limit                uint32   `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TrashListRequest) Reset()         { *m = TrashListRequest{} }
+func (m *TrashListRequest) String() string { return proto.CompactTextString(m) }
+func (*TrashListRequest) ProtoMessage()    {}
+func (*TrashListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{11}
+}
+func (m *TrashListRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TrashListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TrashListRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TrashListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TrashListRequest.Merge(m, src)
+}
+func (m *TrashListRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *TrashListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TrashListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TrashListRequest proto.InternalMessageInfo
+
+func (m *TrashListRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type TrashEntry struct {
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Val string `protobuf:"bytes,2,opt,name=val,proto3" json:"val,omitempty"`
+	// deleted_at is when the entry was deleted in unix nanoseconds.
+	DeletedAt            int64    `protobuf:"varint,3,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TrashEntry) Reset()         { *m = TrashEntry{} }
+func (m *TrashEntry) String() string { return proto.CompactTextString(m) }
+func (*TrashEntry) ProtoMessage()    {}
+func (*TrashEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{12}
+}
+func (m *TrashEntry) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TrashEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TrashEntry.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TrashEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TrashEntry.Merge(m, src)
+}
+func (m *TrashEntry) XXX_Size() int {
+	return m.Size()
+}
+func (m *TrashEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_TrashEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TrashEntry proto.InternalMessageInfo
+
+func (m *TrashEntry) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *TrashEntry) GetVal() string {
+	if m != nil {
+		return m.Val
+	}
+	return ""
+}
+
+func (m *TrashEntry) GetDeletedAt() int64 {
+	if m != nil {
+		return m.DeletedAt
+	}
+	return 0
+}
+
+type TrashEntries struct {
+	Entries              []*TrashEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *TrashEntries) Reset()         { *m = TrashEntries{} }
+func (m *TrashEntries) String() string { return proto.CompactTextString(m) }
+func (*TrashEntries) ProtoMessage()    {}
+func (*TrashEntries) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d065b70573ae483, []int{13}
+}
+func (m *TrashEntries) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TrashEntries) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TrashEntries.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TrashEntries) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TrashEntries.Merge(m, src)
+}
+func (m *TrashEntries) XXX_Size() int {
+	return m.Size()
+}
+func (m *TrashEntries) XXX_DiscardUnknown() {
+	xxx_messageInfo_TrashEntries.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TrashEntries proto.InternalMessageInfo
+
+func (m *TrashEntries) GetEntries() []*TrashEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*CompareAndSetRequest)(nil), "meta_halodb.CompareAndSetRequest")
+	proto.RegisterType((*IndexQueryRequest)(nil), "meta_halodb.IndexQueryRequest")
+	proto.RegisterType((*NamespaceRequest)(nil), "meta_halodb.NamespaceRequest")
+	proto.RegisterType((*NamespaceStats)(nil), "meta_halodb.NamespaceStats")
+	proto.RegisterType((*MetaVersion)(nil), "meta_halodb.MetaVersion")
+	proto.RegisterType((*MetaHistory)(nil), "meta_halodb.MetaHistory")
+	proto.RegisterType((*RollbackRequest)(nil), "meta_halodb.RollbackRequest")
+	proto.RegisterType((*TransactionOperation)(nil), "meta_halodb.TransactionOperation")
+	proto.RegisterType((*TransactionRequest)(nil), "meta_halodb.TransactionRequest")
+	proto.RegisterType((*TransactionResult)(nil), "meta_halodb.TransactionResult")
+	proto.RegisterType((*TransactionResponse)(nil), "meta_halodb.TransactionResponse")
+	proto.RegisterType((*TrashListRequest)(nil), "meta_halodb.TrashListRequest")
+	proto.RegisterType((*TrashEntry)(nil), "meta_halodb.TrashEntry")
+	proto.RegisterType((*TrashEntries)(nil), "meta_halodb.TrashEntries")
+}
+
+func init() { proto.RegisterFile("extension.proto", fileDescriptor_2d065b70573ae483) }
+
+var fileDescriptor_2d065b70573ae483 = []byte{
+	// 912 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0x5f, 0x6f, 0x1b, 0x45,
+	0x10, 0xb7, 0xe3, 0xfc, 0xb1, 0xc7, 0x75, 0x12, 0x2f, 0x06, 0x8c, 0xdb, 0x86, 0xb0, 0x4f, 0xe1,
+	0xa1, 0x36, 0x14, 0x90, 0x0a, 0x02, 0x82, 0x21, 0x51, 0x5b, 0x01, 0xad, 0xb8, 0xb4, 0xa9, 0xd4,
+	0x97, 0x68, 0x7d, 0x37, 0x49, 0x4e, 0xb9, 0xbb, 0xbd, 0xde, 0xae, 0x4d, 0xfc, 0xc4, 0xb7, 0x82,
+	0xaf, 0xc0, 0x23, 0x1f, 0x01, 0xe5, 0x93, 0xa0, 0xdd, 0xdb, 0x3d, 0xfb, 0xfe, 0xb8, 0x48, 0xe9,
+	0xdb, 0xce, 0xdc, 0xec, 0x6f, 0x67, 0x7e, 0x33, 0xbf, 0xd1, 0xc1, 0x0e, 0x5e, 0x4b, 0x8c, 0x84,
+	0xcf, 0xa3, 0x61, 0x9c, 0x70, 0xc9, 0x49, 0x3b, 0x44, 0xc9, 0xce, 0x2e, 0x59, 0xc0, 0xbd, 0xc9,
+	0xa0, 0x13, 0xb3, 0x79, 0xc0, 0x99, 0x97, 0x7e, 0xa3, 0xa7, 0xd0, 0xfb, 0x89, 0x87, 0x31, 0x4b,
+	0x70, 0x1c, 0x79, 0x27, 0x28, 0x1d, 0x7c, 0x33, 0x45, 0x21, 0xc9, 0x2e, 0x34, 0xae, 0x70, 0xde,
+	0xaf, 0xef, 0xd7, 0x0f, 0x5a, 0x8e, 0x3a, 0x92, 0x01, 0x34, 0xf1, 0x3a, 0x46, 0x57, 0xa2, 0xd7,
+	0x5f, 0xd3, 0xee, 0xcc, 0x56, 0xd1, 0x33, 0x16, 0xf4, 0x1b, 0x69, 0xf4, 0x8c, 0x05, 0xf4, 0x10,
+	0xba, 0x4f, 0x23, 0x0f, 0xaf, 0x7f, 0x9b, 0x62, 0x32, 0xb7, 0xa0, 0x3d, 0xd8, 0x38, 0xf7, 0x31,
+	0xf0, 0x0c, 0x6c, 0x6a, 0x28, 0xef, 0x8c, 0x05, 0x53, 0x34, 0xa8, 0xa9, 0x41, 0x3f, 0x83, 0xdd,
+	0x67, 0x2c, 0x44, 0x11, 0x33, 0x17, 0xed, 0xfd, 0x7b, 0xd0, 0x8a, 0xac, 0xcf, 0x60, 0x2c, 0x1c,
+	0xf4, 0x35, 0x6c, 0x67, 0x37, 0x4e, 0x24, 0x93, 0xe2, 0xed, 0xf1, 0xea, 0x5d, 0x97, 0x4f, 0x23,
+	0xa9, 0xdf, 0x6d, 0x38, 0xa9, 0xa1, 0xbc, 0x6f, 0xa6, 0x5c, 0x32, 0x5d, 0x4c, 0xc3, 0x49, 0x0d,
+	0xfa, 0x0a, 0xda, 0xbf, 0xa2, 0x64, 0xa7, 0x98, 0x28, 0x5e, 0x49, 0x1f, 0xb6, 0x66, 0xe9, 0x51,
+	0xc3, 0xae, 0x3b, 0xd6, 0xb4, 0x4c, 0xac, 0x65, 0x4c, 0xa8, 0x24, 0xa4, 0x1f, 0xa2, 0x90, 0x2c,
+	0x8c, 0x0d, 0xe8, 0xc2, 0x41, 0x5f, 0xa6, 0xc0, 0x4f, 0x7c, 0x21, 0x79, 0x32, 0xaf, 0xa0, 0xfd,
+	0x4b, 0x68, 0x1a, 0x6c, 0xd1, 0x5f, 0xdb, 0x6f, 0x1c, 0xb4, 0x1f, 0xf6, 0x87, 0x4b, 0xfd, 0x1c,
+	0x2e, 0xa5, 0xe5, 0x64, 0x91, 0xf4, 0x3b, 0xd8, 0x71, 0x78, 0x10, 0x4c, 0x98, 0x7b, 0xb5, 0xba,
+	0xa3, 0x4b, 0x55, 0xac, 0xe5, 0xaa, 0xa0, 0x7f, 0xd6, 0xa1, 0xf7, 0x22, 0x61, 0x91, 0x60, 0xae,
+	0xf4, 0x79, 0xf4, 0x3c, 0xc6, 0x84, 0x49, 0x53, 0x5e, 0x01, 0xa4, 0x5c, 0xf0, 0x07, 0xb0, 0xe9,
+	0x61, 0x80, 0x12, 0x75, 0xb5, 0x4d, 0xc7, 0x58, 0xe4, 0x2e, 0xb4, 0xfc, 0xf3, 0x33, 0x36, 0x11,
+	0x18, 0xc9, 0xfe, 0xba, 0xfe, 0xd4, 0xf4, 0xcf, 0xc7, 0xda, 0xce, 0x4d, 0xd7, 0x46, 0x61, 0xba,
+	0x3e, 0x85, 0x5d, 0x7b, 0x3e, 0xb3, 0x09, 0x6f, 0xea, 0x84, 0x77, 0xac, 0xdf, 0x30, 0x40, 0x5f,
+	0x01, 0x59, 0xca, 0xdb, 0x96, 0x3e, 0x06, 0xe0, 0xb6, 0x04, 0xd1, 0xaf, 0x6b, 0x16, 0x3f, 0xc9,
+	0xb1, 0x58, 0x55, 0xac, 0xb3, 0x74, 0x89, 0xfe, 0x01, 0xdd, 0x1c, 0xb0, 0x98, 0x06, 0x55, 0x94,
+	0x12, 0x58, 0x77, 0xb9, 0x97, 0x8e, 0x72, 0xc7, 0xd1, 0x67, 0x45, 0x73, 0x88, 0x42, 0xb0, 0x0b,
+	0x34, 0x02, 0xb1, 0xa6, 0xe5, 0x6e, 0x7d, 0xc1, 0xdd, 0x52, 0x4b, 0x36, 0xf2, 0x2d, 0x09, 0xe1,
+	0xbd, 0x7c, 0x02, 0x31, 0x8f, 0x04, 0xaa, 0xe9, 0x72, 0x79, 0x18, 0xfa, 0x52, 0x11, 0x57, 0xd7,
+	0xa4, 0x2e, 0x1c, 0xe4, 0x11, 0x6c, 0x25, 0x3a, 0x55, 0x3b, 0x3b, 0x7b, 0xab, 0xaa, 0x4e, 0x2b,
+	0x72, 0x6c, 0x38, 0x3d, 0x80, 0xdd, 0x17, 0x09, 0x13, 0x97, 0xbf, 0xf8, 0x42, 0x2e, 0xc9, 0x37,
+	0xf0, 0x43, 0x5f, 0xea, 0x77, 0x3a, 0x4e, 0x6a, 0xd0, 0xe7, 0x00, 0x3a, 0xf2, 0x38, 0x92, 0x95,
+	0x03, 0x5c, 0x1e, 0x90, 0xfb, 0x00, 0xe9, 0x48, 0x78, 0x67, 0x4c, 0x5a, 0x49, 0x18, 0xcf, 0x58,
+	0xd2, 0x31, 0xdc, 0xc9, 0x00, 0x7d, 0x14, 0xe4, 0x73, 0xd8, 0xc2, 0xf4, 0x68, 0x5a, 0xf7, 0x61,
+	0xb1, 0x08, 0xf3, 0xb8, 0x63, 0xe3, 0x1e, 0xfe, 0xd5, 0x84, 0x8e, 0x12, 0xc6, 0xb1, 0xdd, 0x84,
+	0xe4, 0x6b, 0xd8, 0x39, 0x41, 0xa9, 0x7c, 0x4f, 0xed, 0xc8, 0xf5, 0x86, 0x76, 0x15, 0x2a, 0xf7,
+	0xf0, 0x67, 0x9c, 0x9f, 0xb2, 0x60, 0xb0, 0x9d, 0x79, 0x8f, 0xc3, 0x58, 0xce, 0x69, 0x8d, 0x3c,
+	0x81, 0x6e, 0x6e, 0x45, 0xaa, 0x68, 0x92, 0x1f, 0x9f, 0xaa, 0x15, 0x5a, 0x81, 0xf4, 0x2d, 0x74,
+	0x1f, 0x9b, 0x24, 0x22, 0xd5, 0x57, 0x1c, 0x07, 0x01, 0xe9, 0xe6, 0xd3, 0x50, 0x39, 0x90, 0x52,
+	0x66, 0x82, 0xd6, 0xc8, 0x21, 0xf4, 0x8e, 0x34, 0x49, 0xb7, 0x05, 0x18, 0x43, 0x4b, 0xaf, 0x63,
+	0x5d, 0x40, 0x7e, 0x12, 0x4a, 0xbb, 0x7a, 0x05, 0xc4, 0xb3, 0xd2, 0x8e, 0xbd, 0x9f, 0xc3, 0x29,
+	0xae, 0xec, 0xc1, 0xdd, 0xea, 0xcf, 0xfa, 0x2e, 0xad, 0x91, 0x1f, 0xa0, 0x73, 0x94, 0xf0, 0x38,
+	0xf3, 0xff, 0x1f, 0x5c, 0x99, 0xd3, 0x23, 0x25, 0xcc, 0x69, 0xe4, 0x32, 0x89, 0xef, 0x80, 0x72,
+	0x08, 0xdb, 0xa6, 0x33, 0x76, 0x13, 0x77, 0x4b, 0xf5, 0x0f, 0xca, 0x8b, 0xd7, 0x04, 0xd3, 0x1a,
+	0xf9, 0x1e, 0xee, 0xd8, 0x85, 0xab, 0xe9, 0xbd, 0x97, 0x8b, 0x2d, 0xec, 0xe2, 0x8a, 0x04, 0x1c,
+	0x68, 0x2f, 0xa9, 0x91, 0x7c, 0xbc, 0x5a, 0xa7, 0x29, 0xc2, 0xfe, 0x5b, 0x84, 0xac, 0x37, 0x03,
+	0xad, 0x91, 0xc7, 0xd0, 0x52, 0xf2, 0xd5, 0x02, 0x29, 0x50, 0x52, 0xd4, 0xf6, 0xe0, 0xa3, 0x6a,
+	0x4d, 0xf9, 0xa8, 0xba, 0xf4, 0x15, 0xb4, 0x1d, 0x54, 0x95, 0xea, 0xd1, 0xab, 0xa2, 0xa6, 0x3c,
+	0x83, 0xb4, 0x46, 0x1e, 0x41, 0xeb, 0xe4, 0x77, 0x16, 0x2b, 0x8f, 0x20, 0xef, 0x57, 0xa9, 0x4d,
+	0x14, 0xc7, 0x4c, 0xf9, 0x68, 0x8d, 0x7c, 0x93, 0xa9, 0x55, 0x98, 0x41, 0x5f, 0x75, 0xbf, 0x52,
+	0x64, 0x2f, 0x23, 0x71, 0xcb, 0xdb, 0x3f, 0x3a, 0x7f, 0xdf, 0xec, 0xd5, 0xff, 0xb9, 0xd9, 0xab,
+	0xff, 0x7b, 0xb3, 0x57, 0x7f, 0x7d, 0x74, 0xe1, 0xcb, 0xcb, 0xe9, 0x64, 0xe8, 0xf2, 0x70, 0x94,
+	0xf8, 0xd1, 0xf5, 0x68, 0xc6, 0x02, 0xef, 0x81, 0x62, 0xea, 0x41, 0xca, 0xd4, 0x28, 0xbe, 0xba,
+	0x18, 0x29, 0x7b, 0x64, 0x6c, 0x16, 0xfb, 0x62, 0x74, 0x91, 0xc4, 0xee, 0x28, 0xfb, 0x0b, 0x9b,
+	0x6c, 0xea, 0x5f, 0xad, 0x2f, 0xfe, 0x1b, 0x00, 0x86, 0xea, 0x50, 0xd7, 0x99, 0x09, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// MetaExtensionClient is the client API for MetaExtension service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type MetaExtensionClient interface {
+	// SetMetaIfAbsent stores the entry only when the key does not exist.
+	// It fails with ALREADY_EXISTS otherwise.
+	SetMetaIfAbsent(ctx context.Context, in *payload.Meta_KeyVal, opts ...grpc.CallOption) (*payload.Empty, error)
+	// CompareAndSetMeta stores the entry only when the current value of the key is the expected one.
+	// It fails with FAILED_PRECONDITION otherwise.
+	CompareAndSetMeta(ctx context.Context, in *CompareAndSetRequest, opts ...grpc.CallOption) (*payload.Empty, error)
+	// GetMetaInverseAll returns all the keys holding the value, oldest first.
+	GetMetaInverseAll(ctx context.Context, in *payload.Meta_Val, opts ...grpc.CallOption) (*payload.Meta_Keys, error)
+	// DeleteMetaInverseAll deletes the inverse entry of the value and returns all the keys it held.
+	DeleteMetaInverseAll(ctx context.Context, in *payload.Meta_Val, opts ...grpc.CallOption) (*payload.Meta_Keys, error)
+	// QueryMeta returns the keys whose JSON value has the value in the indexed field.
+	QueryMeta(ctx context.Context, in *IndexQueryRequest, opts ...grpc.CallOption) (*payload.Meta_Keys, error)
+	// NamespaceStats returns the number of entries and the quota of the namespace.
+	NamespaceStats(ctx context.Context, in *NamespaceRequest, opts ...grpc.CallOption) (*NamespaceStats, error)
+	// DropNamespace makes all the entries of the namespace unreachable.
+	DropNamespace(ctx context.Context, in *NamespaceRequest, opts ...grpc.CallOption) (*payload.Empty, error)
+	// TruncateNamespace removes the data directory of a namespace with a path, and frees its disk space.
+	// It fails with FAILED_PRECONDITION for the namespaces stored in the default HaloDB.
+	TruncateNamespace(ctx context.Context, in *NamespaceRequest, opts ...grpc.CallOption) (*payload.Empty, error)
+	// GetMetaHistory returns the recorded versions of the key, oldest first.
+	// It fails with FAILED_PRECONDITION when the history is not enabled.
+	GetMetaHistory(ctx context.Context, in *payload.Meta_Key, opts ...grpc.CallOption) (*MetaHistory, error)
+	// RollbackMeta sets the value of the recorded version to the key as a new version.
+	// It fails with NOT_FOUND when the version is not recorded.
+	RollbackMeta(ctx context.Context, in *RollbackRequest, opts ...grpc.CallOption) (*payload.Empty, error)
+	// Transaction applies the operations in order and commits them atomically.
+	// The conditions of each operation are checked against the entries left by the previous ones.
+	// Nothing is applied when any operation fails, and the results report the outcome of each one.
+	// It fails with FAILED_PRECONDITION when the asynchronous write journal is not enabled.
+	Transaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	// ListTrash returns the soft deleted entries, oldest first.
+	// It fails with FAILED_PRECONDITION when the trash is not enabled.
+	ListTrash(ctx context.Context, in *TrashListRequest, opts ...grpc.CallOption) (*TrashEntries, error)
+	// RestoreMeta sets the soft deleted entry of the key again and returns its value.
+	// It fails with ALREADY_EXISTS when the key has been set since it was deleted.
+	RestoreMeta(ctx context.Context, in *payload.Meta_Key, opts ...grpc.CallOption) (*payload.Meta_Val, error)
+	// SwapMetas stores the entries like SetMetas and returns the previous values, empty for the new keys.
+	// The router calls it on the owners of the keys to find the inverse entries to move.
+	SwapMetas(ctx context.Context, in *payload.Meta_KeyVals, opts ...grpc.CallOption) (*payload.Meta_Vals, error)
+	// SetMetasInverse stores only the inverse entries of the pairs.
+	// The router calls it on the owners of the values which do not own the keys.
+	// It fails with FAILED_PRECONDITION under the multi policy.
+	SetMetasInverse(ctx context.Context, in *payload.Meta_KeyVals, opts ...grpc.CallOption) (*payload.Empty, error)
+	// UnsetMetasInverse deletes the inverse entries of the values which still point at the keys of the pairs.
+	UnsetMetasInverse(ctx context.Context, in *payload.Meta_KeyVals, opts ...grpc.CallOption) (*payload.Empty, error)
+}
+
+type metaExtensionClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMetaExtensionClient(cc *grpc.ClientConn) MetaExtensionClient {
+	return &metaExtensionClient{cc}
+}
+
+func (c *metaExtensionClient) SetMetaIfAbsent(ctx context.Context, in *payload.Meta_KeyVal, opts ...grpc.CallOption) (*payload.Empty, error) {
+	out := new(payload.Empty)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/SetMetaIfAbsent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) CompareAndSetMeta(ctx context.Context, in *CompareAndSetRequest, opts ...grpc.CallOption) (*payload.Empty, error) {
+	out := new(payload.Empty)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/CompareAndSetMeta", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) GetMetaInverseAll(ctx context.Context, in *payload.Meta_Val, opts ...grpc.CallOption) (*payload.Meta_Keys, error) {
+	out := new(payload.Meta_Keys)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/GetMetaInverseAll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) DeleteMetaInverseAll(ctx context.Context, in *payload.Meta_Val, opts ...grpc.CallOption) (*payload.Meta_Keys, error) {
+	out := new(payload.Meta_Keys)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/DeleteMetaInverseAll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) QueryMeta(ctx context.Context, in *IndexQueryRequest, opts ...grpc.CallOption) (*payload.Meta_Keys, error) {
+	out := new(payload.Meta_Keys)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/QueryMeta", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) NamespaceStats(ctx context.Context, in *NamespaceRequest, opts ...grpc.CallOption) (*NamespaceStats, error) {
+	out := new(NamespaceStats)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/NamespaceStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) DropNamespace(ctx context.Context, in *NamespaceRequest, opts ...grpc.CallOption) (*payload.Empty, error) {
+	out := new(payload.Empty)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/DropNamespace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) TruncateNamespace(ctx context.Context, in *NamespaceRequest, opts ...grpc.CallOption) (*payload.Empty, error) {
+	out := new(payload.Empty)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/TruncateNamespace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) GetMetaHistory(ctx context.Context, in *payload.Meta_Key, opts ...grpc.CallOption) (*MetaHistory, error) {
+	out := new(MetaHistory)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/GetMetaHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) RollbackMeta(ctx context.Context, in *RollbackRequest, opts ...grpc.CallOption) (*payload.Empty, error) {
+	out := new(payload.Empty)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/RollbackMeta", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) Transaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/Transaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) ListTrash(ctx context.Context, in *TrashListRequest, opts ...grpc.CallOption) (*TrashEntries, error) {
+	out := new(TrashEntries)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/ListTrash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) RestoreMeta(ctx context.Context, in *payload.Meta_Key, opts ...grpc.CallOption) (*payload.Meta_Val, error) {
+	out := new(payload.Meta_Val)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/RestoreMeta", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) SwapMetas(ctx context.Context, in *payload.Meta_KeyVals, opts ...grpc.CallOption) (*payload.Meta_Vals, error) {
+	out := new(payload.Meta_Vals)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/SwapMetas", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) SetMetasInverse(ctx context.Context, in *payload.Meta_KeyVals, opts ...grpc.CallOption) (*payload.Empty, error) {
+	out := new(payload.Empty)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/SetMetasInverse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaExtensionClient) UnsetMetasInverse(ctx context.Context, in *payload.Meta_KeyVals, opts ...grpc.CallOption) (*payload.Empty, error) {
+	out := new(payload.Empty)
+	err := c.cc.Invoke(ctx, "/meta_halodb.MetaExtension/UnsetMetasInverse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MetaExtensionServer is the server API for MetaExtension service.
+type MetaExtensionServer interface {
+	// SetMetaIfAbsent stores the entry only when the key does not exist.
+	// It fails with ALREADY_EXISTS otherwise.
+	SetMetaIfAbsent(context.Context, *payload.Meta_KeyVal) (*payload.Empty, error)
+	// CompareAndSetMeta stores the entry only when the current value of the key is the expected one.
+	// It fails with FAILED_PRECONDITION otherwise.
+	CompareAndSetMeta(context.Context, *CompareAndSetRequest) (*payload.Empty, error)
+	// GetMetaInverseAll returns all the keys holding the value, oldest first.
+	GetMetaInverseAll(context.Context, *payload.Meta_Val) (*payload.Meta_Keys, error)
+	// DeleteMetaInverseAll deletes the inverse entry of the value and returns all the keys it held.
+	DeleteMetaInverseAll(context.Context, *payload.Meta_Val) (*payload.Meta_Keys, error)
+	// QueryMeta returns the keys whose JSON value has the value in the indexed field.
+	QueryMeta(context.Context, *IndexQueryRequest) (*payload.Meta_Keys, error)
+	// NamespaceStats returns the number of entries and the quota of the namespace.
+	NamespaceStats(context.Context, *NamespaceRequest) (*NamespaceStats, error)
+	// DropNamespace makes all the entries of the namespace unreachable.
+	DropNamespace(context.Context, *NamespaceRequest) (*payload.Empty, error)
+	// TruncateNamespace removes the data directory of a namespace with a path, and frees its disk space.
+	// It fails with FAILED_PRECONDITION for the namespaces stored in the default HaloDB.
+	TruncateNamespace(context.Context, *NamespaceRequest) (*payload.Empty, error)
+	// GetMetaHistory returns the recorded versions of the key, oldest first.
+	// It fails with FAILED_PRECONDITION when the history is not enabled.
+	GetMetaHistory(context.Context, *payload.Meta_Key) (*MetaHistory, error)
+	// RollbackMeta sets the value of the recorded version to the key as a new version.
+	// It fails with NOT_FOUND when the version is not recorded.
+	RollbackMeta(context.Context, *RollbackRequest) (*payload.Empty, error)
+	// Transaction applies the operations in order and commits them atomically.
+	// The conditions of each operation are checked against the entries left by the previous ones.
+	// Nothing is applied when any operation fails, and the results report the outcome of each one.
+	// It fails with FAILED_PRECONDITION when the asynchronous write journal is not enabled.
+	Transaction(context.Context, *TransactionRequest) (*TransactionResponse, error)
+	// ListTrash returns the soft deleted entries, oldest first.
+	// It fails with FAILED_PRECONDITION when the trash is not enabled.
+	ListTrash(context.Context, *TrashListRequest) (*TrashEntries, error)
+	// RestoreMeta sets the soft deleted entry of the key again and returns its value.
+	// It fails with ALREADY_EXISTS when the key has been set since it was deleted.
+	RestoreMeta(context.Context, *payload.Meta_Key) (*payload.Meta_Val, error)
+	// SwapMetas stores the entries like SetMetas and returns the previous values, empty for the new keys.
+	// The router calls it on the owners of the keys to find the inverse entries to move.
+	SwapMetas(context.Context, *payload.Meta_KeyVals) (*payload.Meta_Vals, error)
+	// SetMetasInverse stores only the inverse entries of the pairs.
+	// The router calls it on the owners of the values which do not own the keys.
+	// It fails with FAILED_PRECONDITION under the multi policy.
+	SetMetasInverse(context.Context, *payload.Meta_KeyVals) (*payload.Empty, error)
+	// UnsetMetasInverse deletes the inverse entries of the values which still point at the keys of the pairs.
+	UnsetMetasInverse(context.Context, *payload.Meta_KeyVals) (*payload.Empty, error)
+}
+
+// UnimplementedMetaExtensionServer can be embedded to have forward compatible implementations.
+type UnimplementedMetaExtensionServer struct {
+}
+
+func (*UnimplementedMetaExtensionServer) SetMetaIfAbsent(ctx context.Context, req *payload.Meta_KeyVal) (*payload.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMetaIfAbsent not implemented")
+}
+func (*UnimplementedMetaExtensionServer) CompareAndSetMeta(ctx context.Context, req *CompareAndSetRequest) (*payload.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareAndSetMeta not implemented")
+}
+func (*UnimplementedMetaExtensionServer) GetMetaInverseAll(ctx context.Context, req *payload.Meta_Val) (*payload.Meta_Keys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetaInverseAll not implemented")
+}
+func (*UnimplementedMetaExtensionServer) DeleteMetaInverseAll(ctx context.Context, req *payload.Meta_Val) (*payload.Meta_Keys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMetaInverseAll not implemented")
+}
+func (*UnimplementedMetaExtensionServer) QueryMeta(ctx context.Context, req *IndexQueryRequest) (*payload.Meta_Keys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryMeta not implemented")
+}
+func (*UnimplementedMetaExtensionServer) NamespaceStats(ctx context.Context, req *NamespaceRequest) (*NamespaceStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NamespaceStats not implemented")
+}
+func (*UnimplementedMetaExtensionServer) DropNamespace(ctx context.Context, req *NamespaceRequest) (*payload.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropNamespace not implemented")
+}
+func (*UnimplementedMetaExtensionServer) TruncateNamespace(ctx context.Context, req *NamespaceRequest) (*payload.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TruncateNamespace not implemented")
+}
+func (*UnimplementedMetaExtensionServer) GetMetaHistory(ctx context.Context, req *payload.Meta_Key) (*MetaHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetaHistory not implemented")
+}
+func (*UnimplementedMetaExtensionServer) RollbackMeta(ctx context.Context, req *RollbackRequest) (*payload.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackMeta not implemented")
+}
+func (*UnimplementedMetaExtensionServer) Transaction(ctx context.Context, req *TransactionRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transaction not implemented")
+}
+func (*UnimplementedMetaExtensionServer) ListTrash(ctx context.Context, req *TrashListRequest) (*TrashEntries, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (*UnimplementedMetaExtensionServer) RestoreMeta(ctx context.Context, req *payload.Meta_Key) (*payload.Meta_Val, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreMeta not implemented")
+}
+func (*UnimplementedMetaExtensionServer) SwapMetas(ctx context.Context, req *payload.Meta_KeyVals) (*payload.Meta_Vals, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SwapMetas not implemented")
+}
+func (*UnimplementedMetaExtensionServer) SetMetasInverse(ctx context.Context, req *payload.Meta_KeyVals) (*payload.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMetasInverse not implemented")
+}
+func (*UnimplementedMetaExtensionServer) UnsetMetasInverse(ctx context.Context, req *payload.Meta_KeyVals) (*payload.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnsetMetasInverse not implemented")
+}
+
+func RegisterMetaExtensionServer(s *grpc.Server, srv MetaExtensionServer) {
+	s.RegisterService(&_MetaExtension_serviceDesc, srv)
+}
+
+func _MetaExtension_SetMetaIfAbsent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(payload.Meta_KeyVal)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).SetMetaIfAbsent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/SetMetaIfAbsent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).SetMetaIfAbsent(ctx, req.(*payload.Meta_KeyVal))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_CompareAndSetMeta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareAndSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).CompareAndSetMeta(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/CompareAndSetMeta",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).CompareAndSetMeta(ctx, req.(*CompareAndSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_GetMetaInverseAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(payload.Meta_Val)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).GetMetaInverseAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/GetMetaInverseAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).GetMetaInverseAll(ctx, req.(*payload.Meta_Val))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_DeleteMetaInverseAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(payload.Meta_Val)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).DeleteMetaInverseAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/DeleteMetaInverseAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).DeleteMetaInverseAll(ctx, req.(*payload.Meta_Val))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_QueryMeta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IndexQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).QueryMeta(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/QueryMeta",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).QueryMeta(ctx, req.(*IndexQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_NamespaceStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NamespaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).NamespaceStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/NamespaceStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).NamespaceStats(ctx, req.(*NamespaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_DropNamespace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NamespaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).DropNamespace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/DropNamespace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).DropNamespace(ctx, req.(*NamespaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_TruncateNamespace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NamespaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).TruncateNamespace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/TruncateNamespace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).TruncateNamespace(ctx, req.(*NamespaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_GetMetaHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(payload.Meta_Key)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).GetMetaHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/GetMetaHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).GetMetaHistory(ctx, req.(*payload.Meta_Key))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_RollbackMeta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).RollbackMeta(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/RollbackMeta",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).RollbackMeta(ctx, req.(*RollbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_Transaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).Transaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/Transaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).Transaction(ctx, req.(*TransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrashListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/ListTrash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).ListTrash(ctx, req.(*TrashListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_RestoreMeta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(payload.Meta_Key)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).RestoreMeta(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/RestoreMeta",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).RestoreMeta(ctx, req.(*payload.Meta_Key))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_SwapMetas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(payload.Meta_KeyVals)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).SwapMetas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/SwapMetas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).SwapMetas(ctx, req.(*payload.Meta_KeyVals))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_SetMetasInverse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(payload.Meta_KeyVals)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).SetMetasInverse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/SetMetasInverse",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).SetMetasInverse(ctx, req.(*payload.Meta_KeyVals))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaExtension_UnsetMetasInverse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(payload.Meta_KeyVals)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaExtensionServer).UnsetMetasInverse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/meta_halodb.MetaExtension/UnsetMetasInverse",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaExtensionServer).UnsetMetasInverse(ctx, req.(*payload.Meta_KeyVals))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _MetaExtension_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "meta_halodb.MetaExtension",
+	HandlerType: (*MetaExtensionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetMetaIfAbsent",
+			Handler:    _MetaExtension_SetMetaIfAbsent_Handler,
+		},
+		{
+			MethodName: "CompareAndSetMeta",
+			Handler:    _MetaExtension_CompareAndSetMeta_Handler,
+		},
+		{
+			MethodName: "GetMetaInverseAll",
+			Handler:    _MetaExtension_GetMetaInverseAll_Handler,
+		},
+		{
+			MethodName: "DeleteMetaInverseAll",
+			Handler:    _MetaExtension_DeleteMetaInverseAll_Handler,
+		},
+		{
+			MethodName: "QueryMeta",
+			Handler:    _MetaExtension_QueryMeta_Handler,
+		},
+		{
+			MethodName: "NamespaceStats",
+			Handler:    _MetaExtension_NamespaceStats_Handler,
+		},
+		{
+			MethodName: "DropNamespace",
+			Handler:    _MetaExtension_DropNamespace_Handler,
+		},
+		{
+			MethodName: "TruncateNamespace",
+			Handler:    _MetaExtension_TruncateNamespace_Handler,
+		},
+		{
+			MethodName: "GetMetaHistory",
+			Handler:    _MetaExtension_GetMetaHistory_Handler,
+		},
+		{
+			MethodName: "RollbackMeta",
+			Handler:    _MetaExtension_RollbackMeta_Handler,
+		},
+		{
+			MethodName: "Transaction",
+			Handler:    _MetaExtension_Transaction_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _MetaExtension_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreMeta",
+			Handler:    _MetaExtension_RestoreMeta_Handler,
+		},
+		{
+			MethodName: "SwapMetas",
+			Handler:    _MetaExtension_SwapMetas_Handler,
+		},
+		{
+			MethodName: "SetMetasInverse",
+			Handler:    _MetaExtension_SetMetasInverse_Handler,
+		},
+		{
+			MethodName: "UnsetMetasInverse",
+			Handler:    _MetaExtension_UnsetMetasInverse_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "extension.proto",
+}
+
+func (m *CompareAndSetRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CompareAndSetRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CompareAndSetRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Val) > 0 {
+		i -= len(m.Val)
+		copy(dAtA[i:], m.Val)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Val)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Expected) > 0 {
+		i -= len(m.Expected)
+		copy(dAtA[i:], m.Expected)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Expected)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Key) > 0 {
+		i -= len(m.Key)
+		copy(dAtA[i:], m.Key)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Key)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *IndexQueryRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *IndexQueryRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *IndexQueryRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Value) > 0 {
+		i -= len(m.Value)
+		copy(dAtA[i:], m.Value)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Value)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Field) > 0 {
+		i -= len(m.Field)
+		copy(dAtA[i:], m.Field)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Field)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *NamespaceRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NamespaceRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NamespaceRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Namespace) > 0 {
+		i -= len(m.Namespace)
+		copy(dAtA[i:], m.Namespace)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Namespace)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *NamespaceStats) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NamespaceStats) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NamespaceStats) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Quota != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.Quota))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Count != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.Count))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Namespace) > 0 {
+		i -= len(m.Namespace)
+		copy(dAtA[i:], m.Namespace)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Namespace)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *MetaVersion) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetaVersion) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MetaVersion) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Timestamp != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.Timestamp))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Val) > 0 {
+		i -= len(m.Val)
+		copy(dAtA[i:], m.Val)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Val)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Version != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *MetaHistory) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetaHistory) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MetaHistory) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Versions) > 0 {
+		for iNdEx := len(m.Versions) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Versions[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintExtension(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Key) > 0 {
+		i -= len(m.Key)
+		copy(dAtA[i:], m.Key)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Key)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RollbackRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RollbackRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RollbackRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Version != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Key) > 0 {
+		i -= len(m.Key)
+		copy(dAtA[i:], m.Key)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Key)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransactionOperation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransactionOperation) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransactionOperation) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.ExpectedVersion != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.ExpectedVersion))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.Expected) > 0 {
+		i -= len(m.Expected)
+		copy(dAtA[i:], m.Expected)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Expected)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.IfAbsent {
+		i--
+		if m.IfAbsent {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Delete {
+		i--
+		if m.Delete {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Val) > 0 {
+		i -= len(m.Val)
+		copy(dAtA[i:], m.Val)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Val)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Key) > 0 {
+		i -= len(m.Key)
+		copy(dAtA[i:], m.Key)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Key)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransactionRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransactionRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransactionRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Operations) > 0 {
+		for iNdEx := len(m.Operations) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Operations[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintExtension(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransactionResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransactionResult) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransactionResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Version != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.Val) > 0 {
+		i -= len(m.Val)
+		copy(dAtA[i:], m.Val)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Val)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Message) > 0 {
+		i -= len(m.Message)
+		copy(dAtA[i:], m.Message)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Message)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Code != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.Code))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Key) > 0 {
+		i -= len(m.Key)
+		copy(dAtA[i:], m.Key)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Key)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransactionResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransactionResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransactionResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Results) > 0 {
+		for iNdEx := len(m.Results) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Results[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintExtension(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Committed {
+		i--
+		if m.Committed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TrashListRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TrashListRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TrashListRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Limit != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TrashEntry) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TrashEntry) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TrashEntry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.DeletedAt != 0 {
+		i = encodeVarintExtension(dAtA, i, uint64(m.DeletedAt))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Val) > 0 {
+		i -= len(m.Val)
+		copy(dAtA[i:], m.Val)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Val)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Key) > 0 {
+		i -= len(m.Key)
+		copy(dAtA[i:], m.Key)
+		i = encodeVarintExtension(dAtA, i, uint64(len(m.Key)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TrashEntries) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TrashEntries) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TrashEntries) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Entries) > 0 {
+		for iNdEx := len(m.Entries) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Entries[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintExtension(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintExtension(dAtA []byte, offset int, v uint64) int {
+	offset -= sovExtension(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *CompareAndSetRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	l = len(m.Expected)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	l = len(m.Val)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *IndexQueryRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *NamespaceRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Namespace)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *NamespaceStats) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Namespace)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.Count != 0 {
+		n += 1 + sovExtension(uint64(m.Count))
+	}
+	if m.Quota != 0 {
+		n += 1 + sovExtension(uint64(m.Quota))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *MetaVersion) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Version != 0 {
+		n += 1 + sovExtension(uint64(m.Version))
+	}
+	l = len(m.Val)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovExtension(uint64(m.Timestamp))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *MetaHistory) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if len(m.Versions) > 0 {
+		for _, e := range m.Versions {
+			l = e.Size()
+			n += 1 + l + sovExtension(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RollbackRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.Version != 0 {
+		n += 1 + sovExtension(uint64(m.Version))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TransactionOperation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	l = len(m.Val)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.Delete {
+		n += 2
+	}
+	if m.IfAbsent {
+		n += 2
+	}
+	l = len(m.Expected)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.ExpectedVersion != 0 {
+		n += 1 + sovExtension(uint64(m.ExpectedVersion))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TransactionRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Operations) > 0 {
+		for _, e := range m.Operations {
+			l = e.Size()
+			n += 1 + l + sovExtension(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TransactionResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.Code != 0 {
+		n += 1 + sovExtension(uint64(m.Code))
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	l = len(m.Val)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.Version != 0 {
+		n += 1 + sovExtension(uint64(m.Version))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TransactionResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Committed {
+		n += 2
+	}
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovExtension(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TrashListRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Limit != 0 {
+		n += 1 + sovExtension(uint64(m.Limit))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TrashEntry) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	l = len(m.Val)
+	if l > 0 {
+		n += 1 + l + sovExtension(uint64(l))
+	}
+	if m.DeletedAt != 0 {
+		n += 1 + sovExtension(uint64(m.DeletedAt))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TrashEntries) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Entries) > 0 {
+		for _, e := range m.Entries {
+			l = e.Size()
+			n += 1 + l + sovExtension(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovExtension(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozExtension(x uint64) (n int) {
+	return sovExtension(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *CompareAndSetRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CompareAndSetRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CompareAndSetRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expected", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Expected = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Val", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Val = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *IndexQueryRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: IndexQueryRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: IndexQueryRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NamespaceRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NamespaceRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NamespaceRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Namespace = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NamespaceStats) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NamespaceStats: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NamespaceStats: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Namespace = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			}
+			m.Count = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Count |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Quota", wireType)
+			}
+			m.Quota = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Quota |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetaVersion) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetaVersion: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetaVersion: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Val", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Val = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetaHistory) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetaHistory: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetaHistory: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Versions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Versions = append(m.Versions, &MetaVersion{})
+			if err := m.Versions[len(m.Versions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RollbackRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RollbackRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RollbackRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TransactionOperation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransactionOperation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransactionOperation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Val", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Val = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Delete", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Delete = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IfAbsent", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IfAbsent = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expected", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Expected = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectedVersion", wireType)
+			}
+			m.ExpectedVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpectedVersion |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TransactionRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransactionRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransactionRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operations", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Operations = append(m.Operations, &TransactionOperation{})
+			if err := m.Operations[len(m.Operations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TransactionResult) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransactionResult: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransactionResult: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
+			}
+			m.Code = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Code |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Val", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Val = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TransactionResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransactionResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransactionResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Committed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Committed = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Results = append(m.Results, &TransactionResult{})
+			if err := m.Results[len(m.Results)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TrashListRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TrashListRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TrashListRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TrashEntry) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TrashEntry: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TrashEntry: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Val", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Val = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeletedAt", wireType)
+			}
+			m.DeletedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DeletedAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TrashEntries) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TrashEntries: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TrashEntries: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Entries", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthExtension
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Entries = append(m.Entries, &TrashEntry{})
+			if err := m.Entries[len(m.Entries)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipExtension(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthExtension
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipExtension(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowExtension
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowExtension
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthExtension
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupExtension
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthExtension
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthExtension        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowExtension          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupExtension = fmt.Errorf("proto: unexpected end of group")
+)
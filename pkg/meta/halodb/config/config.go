@@ -6,16 +6,205 @@ import (
 
 type GlobalConfig = config.GlobalConfig
 
+const (
+	// StorageMode serves the meta APIs from the local HaloDB.
+	StorageMode = "storage"
+	// RouterMode forwards the meta APIs to the storage nodes of a cluster.
+	RouterMode = "router"
+)
+
 // Config represent a application setting data content (config.yaml).
 // In K8s environment, this configuration is stored in K8s ConfigMap.
 type Data struct {
 	config.GlobalConfig `json:",inline" yaml:",inline"`
 
+	// Mode represent the running mode (storage or router)
+	Mode string `json:"mode" yaml:"mode"`
+
 	// Server represent all server configurations
 	Server *config.Servers `json:"server_config" yaml:"server_config"`
 
 	// Observability represent observability configurations
 	Observability *config.Observability `json:"observability" yaml:"observability"`
+
+	// Cluster represent cluster configurations used in router mode
+	Cluster *Cluster `json:"cluster" yaml:"cluster"`
+
+	// HaloDB represent HaloDB configurations used in storage mode
+	HaloDB *HaloDB `json:"halodb" yaml:"halodb"`
+}
+
+// HaloDB represent the local HaloDB storage.
+type HaloDB struct {
+	// Path represent the data directory of HaloDB
+	Path string `json:"path" yaml:"path"`
+
+	// TTL represent the entry expiration configurations
+	TTL *TTL `json:"ttl" yaml:"ttl"`
+
+	// Compression represent the value compression configurations
+	Compression *Compression `json:"compression" yaml:"compression"`
+
+	// Encryption represent the encryption at rest configurations
+	Encryption *Encryption `json:"encryption" yaml:"encryption"`
+
+	// Cache represent the read cache configurations
+	Cache *Cache `json:"cache" yaml:"cache"`
+
+	// EnableCoalescing represent whether the concurrent reads of the same key share one native call
+	EnableCoalescing bool `json:"enable_coalescing" yaml:"enable_coalescing"`
+
+	// GroupCommit represent the write batching configurations
+	GroupCommit *GroupCommit `json:"group_commit" yaml:"group_commit"`
+
+	// AsyncWrite represent the journaled asynchronous write configurations
+	AsyncWrite *AsyncWrite `json:"async_write" yaml:"async_write"`
+
+	// Uniqueness represent the policy when a value is set to another key: allow, reject or overwrite
+	Uniqueness string `json:"uniqueness" yaml:"uniqueness"`
+
+	// HistorySize represent the number of versions kept for each key, the history is disabled when it is not positive
+	HistorySize int `json:"history_size" yaml:"history_size"`
+
+	// Versioning represent whether every entry has a version for the optimistic concurrency control
+	Versioning bool `json:"versioning" yaml:"versioning"`
+
+	// Trash represent the soft delete configurations
+	Trash *Trash `json:"trash" yaml:"trash"`
+
+	// JSON represent the JSON value configurations
+	JSON *JSON `json:"json" yaml:"json"`
+
+	// Namespaces represent the allowed namespaces
+	Namespaces []*Namespace `json:"namespaces" yaml:"namespaces"`
+}
+
+// Namespace represent an allowed namespace.
+type Namespace struct {
+	// Name represent the namespace name
+	Name string `json:"name" yaml:"name"`
+
+	// Quota represent the maximum number of entries, it is unlimited when it is not positive
+	Quota int64 `json:"quota" yaml:"quota"`
+
+	// Path represent the dedicated data directory, the namespace shares the default HaloDB when it is empty
+	Path string `json:"path" yaml:"path"`
+
+	// IdleTimeout represent the duration to close the dedicated HaloDB after the last access
+	IdleTimeout string `json:"idle_timeout" yaml:"idle_timeout"`
+
+	// CompactionInterval represent the interval of the compaction window of the dedicated HaloDB
+	CompactionInterval string `json:"compaction_interval" yaml:"compaction_interval"`
+
+	// CompactionWindow represent the duration the compaction runs at the start of every interval
+	CompactionWindow string `json:"compaction_window" yaml:"compaction_window"`
+}
+
+// JSON represent the JSON value configurations.
+type JSON struct {
+	// Enabled represent whether the values must be valid JSON documents
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// IndexFields represent the fields which have secondary indexes, nested fields are separated by '.'
+	IndexFields []string `json:"index_fields" yaml:"index_fields"`
+}
+
+// Compression represent the value compression configurations.
+// Compression is disabled when the algorithm is empty.
+type Compression struct {
+	config.CompressCore `json:",inline" yaml:",inline"`
+
+	// Threshold represent the minimum value size in bytes to be compressed
+	Threshold int `json:"threshold" yaml:"threshold"`
+}
+
+// Encryption represent the encryption at rest configurations.
+type Encryption struct {
+	// Enabled represent whether the values are encrypted
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Keys represent the "<id>:<base64 key>" entries separated by newlines or commas.
+	// The first entry is used for writing, the others are kept for reading until the entries are rewritten.
+	// It can be read from a file (file://path) or an environment variable (_ENV_NAME_).
+	Keys string `json:"keys" yaml:"keys"`
+
+	// EncryptKeys represent whether the keys are also encrypted deterministically
+	EncryptKeys bool `json:"encrypt_keys" yaml:"encrypt_keys"`
+}
+
+// Cache represent the read cache configurations.
+type Cache struct {
+	// Enabled represent whether the read cache is used
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Size represent the maximum number of cached entries
+	Size int `json:"size" yaml:"size"`
+
+	// ExpireDuration represent the lifetime of cached entries
+	ExpireDuration string `json:"expire_duration" yaml:"expire_duration"`
+}
+
+// AsyncWrite represent the journaled asynchronous write configurations.
+type AsyncWrite struct {
+	// Enabled represent whether the writes are acknowledged once they are journaled
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// JournalPath represent the journal file path
+	JournalPath string `json:"journal_path" yaml:"journal_path"`
+
+	// QueueSize represent the maximum number of the mutations waiting to be applied
+	QueueSize int `json:"queue_size" yaml:"queue_size"`
+
+	// RetryDuration represent the interval to retry the failed mutations
+	RetryDuration string `json:"retry_duration" yaml:"retry_duration"`
+
+	// MaxRetries represent how many times a failed mutation is retried before it is dropped
+	MaxRetries int `json:"max_retries" yaml:"max_retries"`
+}
+
+// GroupCommit represent the write batching configurations.
+type GroupCommit struct {
+	// Enabled represent whether the concurrent writes are batched
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Window represent how long a batch waits for more writes
+	Window string `json:"window" yaml:"window"`
+
+	// BatchSize represent the maximum number of writes in a batch
+	BatchSize int `json:"batch_size" yaml:"batch_size"`
+}
+
+// TTL represent the entry expiration configurations.
+type TTL struct {
+	// Enabled represent whether SetMeta(s) accepts TTLs
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// SweepDuration represent the interval of deleting expired entries
+	SweepDuration string `json:"sweep_duration" yaml:"sweep_duration"`
+}
+
+// Trash represent the soft delete configurations.
+type Trash struct {
+	// Enabled represent whether the deleted entries are kept in the trash
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Retention represent how long the deleted entries are kept
+	Retention string `json:"retention" yaml:"retention"`
+
+	// SweepDuration represent the interval of purging the entries over the retention
+	SweepDuration string `json:"sweep_duration" yaml:"sweep_duration"`
+}
+
+// Cluster represent the storage nodes behind a router.
+type Cluster struct {
+	// Peers represent the addresses of the storage nodes
+	Peers []string `json:"peers" yaml:"peers"`
+
+	// VirtualNodes represent the number of points per peer on the hash ring
+	VirtualNodes int `json:"virtual_nodes" yaml:"virtual_nodes"`
+
+	// Client represent the gRPC client configurations for the storage nodes
+	Client *config.GRPCClient `json:"client" yaml:"client"`
 }
 
 func NewConfig(path string) (cfg *Data, err error) {
@@ -29,6 +218,11 @@ func NewConfig(path string) (cfg *Data, err error) {
 		cfg.Bind()
 	}
 
+	cfg.Mode = config.GetActualValue(cfg.Mode)
+	if len(cfg.Mode) == 0 {
+		cfg.Mode = StorageMode
+	}
+
 	if cfg.Server != nil {
 		cfg.Server = cfg.Server.Bind()
 	}
@@ -37,5 +231,145 @@ func NewConfig(path string) (cfg *Data, err error) {
 		cfg.Observability = cfg.Observability.Bind()
 	}
 
+	if cfg.Cluster != nil {
+		cfg.Cluster = cfg.Cluster.Bind()
+	}
+
+	if cfg.HaloDB != nil {
+		cfg.HaloDB = cfg.HaloDB.Bind()
+	} else {
+		cfg.HaloDB = new(HaloDB).Bind()
+	}
+
 	return cfg, nil
 }
+
+func (c *Cluster) Bind() *Cluster {
+	c.Peers = config.GetActualValues(c.Peers)
+
+	if c.Client != nil {
+		c.Client = c.Client.Bind()
+	} else {
+		c.Client = new(config.GRPCClient).Bind()
+	}
+
+	return c
+}
+
+func (h *HaloDB) Bind() *HaloDB {
+	h.Path = config.GetActualValue(h.Path)
+	if len(h.Path) == 0 {
+		h.Path = ".halodb"
+	}
+	h.Uniqueness = config.GetActualValue(h.Uniqueness)
+
+	for _, ns := range h.Namespaces {
+		if ns != nil {
+			ns.Name = config.GetActualValue(ns.Name)
+			ns.Path = config.GetActualValue(ns.Path)
+			ns.IdleTimeout = config.GetActualValue(ns.IdleTimeout)
+			ns.CompactionInterval = config.GetActualValue(ns.CompactionInterval)
+			ns.CompactionWindow = config.GetActualValue(ns.CompactionWindow)
+		}
+	}
+
+	if h.JSON != nil {
+		h.JSON = h.JSON.Bind()
+	} else {
+		h.JSON = new(JSON)
+	}
+
+	if h.TTL != nil {
+		h.TTL = h.TTL.Bind()
+	} else {
+		h.TTL = new(TTL)
+	}
+
+	if h.Trash != nil {
+		h.Trash = h.Trash.Bind()
+	} else {
+		h.Trash = new(Trash)
+	}
+
+	if h.Compression != nil {
+		h.Compression = h.Compression.Bind()
+	} else {
+		h.Compression = new(Compression)
+	}
+
+	if h.Encryption != nil {
+		h.Encryption = h.Encryption.Bind()
+	} else {
+		h.Encryption = new(Encryption)
+	}
+
+	if h.Cache != nil {
+		h.Cache = h.Cache.Bind()
+	} else {
+		h.Cache = new(Cache)
+	}
+
+	if h.GroupCommit != nil {
+		h.GroupCommit = h.GroupCommit.Bind()
+	} else {
+		h.GroupCommit = new(GroupCommit)
+	}
+
+	if h.AsyncWrite != nil {
+		h.AsyncWrite = h.AsyncWrite.Bind()
+	} else {
+		h.AsyncWrite = new(AsyncWrite)
+	}
+
+	return h
+}
+
+func (j *JSON) Bind() *JSON {
+	j.IndexFields = config.GetActualValues(j.IndexFields)
+
+	return j
+}
+
+func (a *AsyncWrite) Bind() *AsyncWrite {
+	a.JournalPath = config.GetActualValue(a.JournalPath)
+	a.RetryDuration = config.GetActualValue(a.RetryDuration)
+
+	return a
+}
+
+func (g *GroupCommit) Bind() *GroupCommit {
+	g.Window = config.GetActualValue(g.Window)
+
+	return g
+}
+
+func (c *Cache) Bind() *Cache {
+	c.ExpireDuration = config.GetActualValue(c.ExpireDuration)
+
+	return c
+}
+
+func (e *Encryption) Bind() *Encryption {
+	e.Keys = config.GetActualValue(e.Keys)
+
+	return e
+}
+
+func (c *Compression) Bind() *Compression {
+	c.CompressCore = *c.CompressCore.Bind()
+
+	return c
+}
+
+func (t *TTL) Bind() *TTL {
+	t.SweepDuration = config.GetActualValue(t.SweepDuration)
+
+	return t
+}
+
+func (t *Trash) Bind() *Trash {
+	t.Retention = config.GetActualValue(t.Retention)
+	t.SweepDuration = config.GetActualValue(t.SweepDuration)
+
+	return t
+}
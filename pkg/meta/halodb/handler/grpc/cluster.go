@@ -0,0 +1,527 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/info"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc"
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc/status"
+	"github.com/rinx/vald-meta-halodb/internal/observability/trace"
+	"github.com/rinx/vald-meta-halodb/internal/safety"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+	"github.com/vdaas/vald/apis/grpc/meta"
+	"github.com/vdaas/vald/apis/grpc/payload"
+)
+
+// cluster forwards the meta APIs to the storage nodes.
+// kv: entries are placed on the owner of the key and vk: entries on the owner of the value,
+// which only receives the inverse entry when it does not own the key.
+type cluster struct {
+	client grpc.Client
+	ring   service.Ring
+}
+
+func NewCluster(opts ...ClusterOption) meta.MetaServer {
+	c := new(cluster)
+
+	for _, opt := range append(defaultClusterOpts, opts...) {
+		opt(c)
+	}
+	return c
+}
+
+func (c *cluster) kvKey(key string) string {
+	return "kv:" + key
+}
+
+func (c *cluster) vkKey(val string) string {
+	return "vk:" + val
+}
+
+func (c *cluster) keyOwner(key string) string {
+	return c.ring.Owner(c.kvKey(key))
+}
+
+func (c *cluster) valOwner(val string) string {
+	return c.ring.Owner(c.vkKey(val))
+}
+
+func (c *cluster) do(ctx context.Context, addr string,
+	f func(ctx context.Context, mc meta.MetaClient, copts ...grpc.CallOption) (interface{}, error)) (interface{}, error) {
+	return c.client.Do(forwardMetadata(ctx), addr, func(ctx context.Context,
+		conn *grpc.ClientConn, copts ...grpc.CallOption) (interface{}, error) {
+		return f(ctx, meta.NewMetaClient(conn), copts...)
+	})
+}
+
+func (c *cluster) doExt(ctx context.Context, addr string,
+	f func(ctx context.Context, ec extension.MetaExtensionClient, copts ...grpc.CallOption) (interface{}, error)) (interface{}, error) {
+	return c.client.Do(forwardMetadata(ctx), addr, func(ctx context.Context,
+		conn *grpc.ClientConn, copts ...grpc.CallOption) (interface{}, error) {
+		return f(ctx, extension.NewMetaExtensionClient(conn), copts...)
+	})
+}
+
+// partition groups the positions of ids by the owner returned from owner.
+func (c *cluster) partition(ids []string, owner func(string) string) map[string][]int {
+	parts := make(map[string][]int)
+	for i, id := range ids {
+		addr := owner(id)
+		parts[addr] = append(parts[addr], i)
+	}
+	return parts
+}
+
+// broadcast calls f for every owner in parts concurrently.
+func (c *cluster) broadcast(ctx context.Context, parts map[string][]int,
+	f func(ctx context.Context, addr string, idxs []int) error) error {
+	eg, ectx := errgroup.New(ctx)
+	for addr, idxs := range parts {
+		addr, idxs := addr, idxs
+		eg.Go(safety.RecoverFunc(func() error {
+			return f(ectx, addr, idxs)
+		}))
+	}
+	return eg.Wait()
+}
+
+// broadcastKeyVals calls f with the pairs of every owner in parts concurrently.
+func (c *cluster) broadcastKeyVals(ctx context.Context, parts map[string][]*payload.Meta_KeyVal,
+	f func(ctx context.Context, ec extension.MetaExtensionClient, kvs *payload.Meta_KeyVals, copts ...grpc.CallOption) (interface{}, error)) error {
+	eg, ectx := errgroup.New(ctx)
+	for addr, kvs := range parts {
+		addr, kvs := addr, kvs
+		eg.Go(safety.RecoverFunc(func() error {
+			_, err := c.doExt(ectx, addr, func(ctx context.Context,
+				ec extension.MetaExtensionClient, copts ...grpc.CallOption) (interface{}, error) {
+				return f(ctx, ec, &payload.Meta_KeyVals{
+					Kvs: kvs,
+				}, copts...)
+			})
+			return err
+		}))
+	}
+	return eg.Wait()
+}
+
+func pickKeyVals(kvs []*payload.Meta_KeyVal, idxs []int) []*payload.Meta_KeyVal {
+	res := make([]*payload.Meta_KeyVal, 0, len(idxs))
+	for _, i := range idxs {
+		res = append(res, kvs[i])
+	}
+	return res
+}
+
+func pick(ids []string, idxs []int) []string {
+	res := make([]string, 0, len(idxs))
+	for _, i := range idxs {
+		res = append(res, ids[i])
+	}
+	return res
+}
+
+func isNotFound(err error) bool {
+	return status.Code(errors.Cause(err)) == status.NotFound
+}
+
+func (c *cluster) GetMeta(ctx context.Context, key *payload.Meta_Key) (*payload.Meta_Val, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.GetMeta")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	res, err := c.do(ctx, c.keyOwner(key.GetKey()), func(ctx context.Context,
+		mc meta.MetaClient, copts ...grpc.CallOption) (interface{}, error) {
+		return mc.GetMeta(ctx, key, copts...)
+	})
+	if err != nil {
+		if isNotFound(err) {
+			log.Warnf("[GetMeta]\tkey %s not found", key.GetKey())
+			if span != nil {
+				span.SetStatus(trace.StatusCodeNotFound(err.Error()))
+			}
+			return nil, status.WrapWithNotFound(fmt.Sprintf("GetMeta API cluster key %s not found", key.GetKey()), err, info.Get())
+		}
+		log.Errorf("[GetMeta]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnavailable(err.Error()))
+		}
+		return nil, status.WrapWithUnavailable(fmt.Sprintf("GetMeta API cluster key %s failed to forward", key.GetKey()), err, info.Get())
+	}
+	return res.(*payload.Meta_Val), nil
+}
+
+func (c *cluster) GetMetas(ctx context.Context, keys *payload.Meta_Keys) (mv *payload.Meta_Vals, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.GetMetas")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	ks := keys.GetKeys()
+	vals := make([]string, len(ks))
+	err = c.broadcast(ctx, c.partition(ks, c.keyOwner), func(ctx context.Context, addr string, idxs []int) error {
+		res, err := c.do(ctx, addr, func(ctx context.Context,
+			mc meta.MetaClient, copts ...grpc.CallOption) (interface{}, error) {
+			return mc.GetMetas(ctx, &payload.Meta_Keys{
+				Keys: pick(ks, idxs),
+			}, copts...)
+		})
+		if err != nil {
+			return err
+		}
+		for i, v := range res.(*payload.Meta_Vals).GetVals() {
+			vals[idxs[i]] = v
+		}
+		return nil
+	})
+	if err != nil {
+		if isNotFound(err) {
+			log.Warnf("[GetMetas]\tkeys %#v not found", ks)
+			if span != nil {
+				span.SetStatus(trace.StatusCodeNotFound(err.Error()))
+			}
+			return nil, status.WrapWithNotFound(fmt.Sprintf("GetMetas API cluster entry keys %#v not found", ks), err, info.Get())
+		}
+		log.Errorf("[GetMetas]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnavailable(err.Error()))
+		}
+		return nil, status.WrapWithUnavailable(fmt.Sprintf("GetMetas API cluster entry keys %#v failed to forward", ks), err, info.Get())
+	}
+	return &payload.Meta_Vals{
+		Vals: vals,
+	}, nil
+}
+
+func (c *cluster) GetMetaInverse(ctx context.Context, val *payload.Meta_Val) (*payload.Meta_Key, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.GetMetaInverse")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	res, err := c.do(ctx, c.valOwner(val.GetVal()), func(ctx context.Context,
+		mc meta.MetaClient, copts ...grpc.CallOption) (interface{}, error) {
+		return mc.GetMetaInverse(ctx, val, copts...)
+	})
+	if err != nil {
+		if isNotFound(err) {
+			log.Warnf("[GetMetaInverse]\tval %s not found", val.GetVal())
+			if span != nil {
+				span.SetStatus(trace.StatusCodeNotFound(err.Error()))
+			}
+			return nil, status.WrapWithNotFound(fmt.Sprintf("GetMetaInverse API cluster val %s not found", val.GetVal()), err, info.Get())
+		}
+		log.Errorf("[GetMetaInverse]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnavailable(err.Error()))
+		}
+		return nil, status.WrapWithUnavailable(fmt.Sprintf("GetMetaInverse API cluster val %s failed to forward", val.GetVal()), err, info.Get())
+	}
+	return res.(*payload.Meta_Key), nil
+}
+
+func (c *cluster) GetMetasInverse(ctx context.Context, vals *payload.Meta_Vals) (mk *payload.Meta_Keys, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.GetMetasInverse")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	vs := vals.GetVals()
+	keys := make([]string, len(vs))
+	err = c.broadcast(ctx, c.partition(vs, c.valOwner), func(ctx context.Context, addr string, idxs []int) error {
+		res, err := c.do(ctx, addr, func(ctx context.Context,
+			mc meta.MetaClient, copts ...grpc.CallOption) (interface{}, error) {
+			return mc.GetMetasInverse(ctx, &payload.Meta_Vals{
+				Vals: pick(vs, idxs),
+			}, copts...)
+		})
+		if err != nil {
+			return err
+		}
+		for i, k := range res.(*payload.Meta_Keys).GetKeys() {
+			keys[idxs[i]] = k
+		}
+		return nil
+	})
+	if err != nil {
+		if isNotFound(err) {
+			log.Warnf("[GetMetasInverse]\tvals %#v not found", vs)
+			if span != nil {
+				span.SetStatus(trace.StatusCodeNotFound(err.Error()))
+			}
+			return nil, status.WrapWithNotFound(fmt.Sprintf("GetMetasInverse API cluster vals %#v not found", vs), err, info.Get())
+		}
+		log.Errorf("[GetMetasInverse]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnavailable(err.Error()))
+		}
+		return nil, status.WrapWithUnavailable(fmt.Sprintf("GetMetasInverse API cluster vals %#v failed to forward", vs), err, info.Get())
+	}
+	return &payload.Meta_Keys{
+		Keys: keys,
+	}, nil
+}
+
+func (c *cluster) SetMeta(ctx context.Context, kv *payload.Meta_KeyVal) (_ *payload.Empty, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.SetMeta")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	_, err = c.SetMetas(ctx, &payload.Meta_KeyVals{
+		Kvs: []*payload.Meta_KeyVal{kv},
+	})
+	if err != nil {
+		log.Errorf("[SetMeta]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnavailable(err.Error()))
+		}
+		return nil, status.WrapWithUnavailable(fmt.Sprintf("SetMeta API cluster key %s val %s failed to store", kv.GetKey(), kv.GetVal()), err, info.Get())
+	}
+	return new(payload.Empty), nil
+}
+
+func (c *cluster) SetMetas(ctx context.Context, kvs *payload.Meta_KeyVals) (_ *payload.Empty, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.SetMetas")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	ps := kvs.GetKvs()
+	keys := make([]string, 0, len(ps))
+	for _, kv := range ps {
+		keys = append(keys, kv.GetKey())
+	}
+	prevs := make([]string, len(ps))
+	err = c.broadcast(ctx, c.partition(keys, c.keyOwner), func(ctx context.Context, addr string, idxs []int) error {
+		res, err := c.doExt(ctx, addr, func(ctx context.Context,
+			ec extension.MetaExtensionClient, copts ...grpc.CallOption) (interface{}, error) {
+			return ec.SwapMetas(ctx, &payload.Meta_KeyVals{
+				Kvs: pickKeyVals(ps, idxs),
+			}, copts...)
+		})
+		if err != nil {
+			return err
+		}
+		for i, v := range res.(*payload.Meta_Vals).GetVals() {
+			prevs[idxs[i]] = v
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("[SetMetas]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnavailable(err.Error()))
+		}
+		return nil, status.WrapWithUnavailable("SetMetas API cluster failed to store", err, info.Get())
+	}
+
+	// the owners of the keys set the inverse entries they own,
+	// the others are set on the owners of the new values, and the previous ones are removed from their owners
+	sets := make(map[string][]*payload.Meta_KeyVal)
+	unsets := make(map[string][]*payload.Meta_KeyVal)
+	for i, kv := range ps {
+		kaddr := c.keyOwner(kv.GetKey())
+		if vaddr := c.valOwner(kv.GetVal()); vaddr != kaddr {
+			sets[vaddr] = append(sets[vaddr], kv)
+		}
+		prev := prevs[i]
+		if len(prev) == 0 || prev == kv.GetVal() {
+			continue
+		}
+		// the owner of the key keeps the previous inverse entry under the allow policy, so it is always removed
+		paddr := c.valOwner(prev)
+		unsets[paddr] = append(unsets[paddr], &payload.Meta_KeyVal{
+			Key: kv.GetKey(),
+			Val: prev,
+		})
+	}
+	err = c.broadcastKeyVals(ctx, sets, func(ctx context.Context,
+		ec extension.MetaExtensionClient, kvs *payload.Meta_KeyVals, copts ...grpc.CallOption) (interface{}, error) {
+		return ec.SetMetasInverse(ctx, kvs, copts...)
+	})
+	if err != nil {
+		log.Errorf("[SetMetas]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnavailable(err.Error()))
+		}
+		return nil, status.WrapWithUnavailable("SetMetas API cluster failed to store the inverse entries", err, info.Get())
+	}
+	// the previous inverse entries are removed after the new ones are set, as a key may be set twice in kvs
+	err = c.broadcastKeyVals(ctx, unsets, func(ctx context.Context,
+		ec extension.MetaExtensionClient, kvs *payload.Meta_KeyVals, copts ...grpc.CallOption) (interface{}, error) {
+		return ec.UnsetMetasInverse(ctx, kvs, copts...)
+	})
+	if err != nil {
+		log.Warnf("[SetMetas]\tfailed to delete the inverse entries of the previous values\t%+v", err)
+	}
+	return new(payload.Empty), nil
+}
+
+func (c *cluster) DeleteMeta(ctx context.Context, key *payload.Meta_Key) (*payload.Meta_Val, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.DeleteMeta")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	mv, err := c.DeleteMetas(ctx, &payload.Meta_Keys{
+		Keys: []string{key.GetKey()},
+	})
+	if err != nil {
+		log.Errorf("[DeleteMeta]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnknown(err.Error()))
+		}
+		return nil, status.WrapWithUnknown(fmt.Sprintf("DeleteMeta API cluster unknown error occurred key %s", key.GetKey()), err, info.Get())
+	}
+	return &payload.Meta_Val{
+		Val: mv.GetVals()[0],
+	}, nil
+}
+
+func (c *cluster) DeleteMetas(ctx context.Context, keys *payload.Meta_Keys) (mv *payload.Meta_Vals, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.DeleteMetas")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	ks := keys.GetKeys()
+	vals := make([]string, len(ks))
+	err = c.broadcast(ctx, c.partition(ks, c.keyOwner), func(ctx context.Context, addr string, idxs []int) error {
+		res, err := c.do(ctx, addr, func(ctx context.Context,
+			mc meta.MetaClient, copts ...grpc.CallOption) (interface{}, error) {
+			return mc.DeleteMetas(ctx, &payload.Meta_Keys{
+				Keys: pick(ks, idxs),
+			}, copts...)
+		})
+		if err != nil {
+			return err
+		}
+		for i, v := range res.(*payload.Meta_Vals).GetVals() {
+			vals[idxs[i]] = v
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("[DeleteMetas]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnknown(err.Error()))
+		}
+		return nil, status.WrapWithUnknown(fmt.Sprintf("DeleteMetas API cluster entry keys %#v unknown error occurred", ks), err, info.Get())
+	}
+
+	// remove the inverse entries from the owners of the values, including the owners of the keys which keep them
+	unsets := make(map[string][]*payload.Meta_KeyVal)
+	for i, k := range ks {
+		if len(vals[i]) != 0 {
+			vaddr := c.valOwner(vals[i])
+			unsets[vaddr] = append(unsets[vaddr], &payload.Meta_KeyVal{
+				Key: k,
+				Val: vals[i],
+			})
+		}
+	}
+	err = c.broadcastKeyVals(ctx, unsets, func(ctx context.Context,
+		ec extension.MetaExtensionClient, kvs *payload.Meta_KeyVals, copts ...grpc.CallOption) (interface{}, error) {
+		return ec.UnsetMetasInverse(ctx, kvs, copts...)
+	})
+	if err != nil {
+		log.Warnf("[DeleteMetas]\tfailed to delete the inverse entries of keys %#v\t%+v", ks, err)
+	}
+
+	return &payload.Meta_Vals{
+		Vals: vals,
+	}, nil
+}
+
+func (c *cluster) DeleteMetaInverse(ctx context.Context, val *payload.Meta_Val) (*payload.Meta_Key, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.DeleteMetaInverse")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	mk, err := c.DeleteMetasInverse(ctx, &payload.Meta_Vals{
+		Vals: []string{val.GetVal()},
+	})
+	if err != nil {
+		log.Errorf("[DeleteMetaInverse]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnknown(err.Error()))
+		}
+		return nil, status.WrapWithUnknown(fmt.Sprintf("DeleteMetaInverse API cluster val %s unknown error occurred", val.GetVal()), err, info.Get())
+	}
+	return &payload.Meta_Key{
+		Key: mk.GetKeys()[0],
+	}, nil
+}
+
+func (c *cluster) DeleteMetasInverse(ctx context.Context, vals *payload.Meta_Vals) (mk *payload.Meta_Keys, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB/cluster.DeleteMetasInverse")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	vs := vals.GetVals()
+	keys := make([]string, len(vs))
+	err = c.broadcast(ctx, c.partition(vs, c.valOwner), func(ctx context.Context, addr string, idxs []int) error {
+		res, err := c.do(ctx, addr, func(ctx context.Context,
+			mc meta.MetaClient, copts ...grpc.CallOption) (interface{}, error) {
+			return mc.DeleteMetasInverse(ctx, &payload.Meta_Vals{
+				Vals: pick(vs, idxs),
+			}, copts...)
+		})
+		if err != nil {
+			return err
+		}
+		for i, k := range res.(*payload.Meta_Keys).GetKeys() {
+			keys[idxs[i]] = k
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("[DeleteMetasInverse]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnknown(err.Error()))
+		}
+		return nil, status.WrapWithUnknown(fmt.Sprintf("DeleteMetasInverse API cluster vals %#v unknown error occurred", vs), err, info.Get())
+	}
+
+	// remove the inverse entries the owners of the keys keep with their entries
+	copies := make(map[string][]string)
+	for i, v := range vs {
+		vaddr, kaddr := c.valOwner(v), c.keyOwner(keys[i])
+		if kaddr != vaddr {
+			copies[kaddr] = append(copies[kaddr], v)
+		}
+	}
+	for addr, cvs := range copies {
+		_, err := c.do(ctx, addr, func(ctx context.Context,
+			mc meta.MetaClient, copts ...grpc.CallOption) (interface{}, error) {
+			return mc.DeleteMetasInverse(ctx, &payload.Meta_Vals{
+				Vals: cvs,
+			}, copts...)
+		})
+		if err != nil {
+			log.Warnf("[DeleteMetasInverse]\tfailed to delete copies of vals %#v on %s\t%+v", cvs, addr, err)
+		}
+	}
+
+	return &payload.Meta_Keys{
+		Keys: keys,
+	}, nil
+}
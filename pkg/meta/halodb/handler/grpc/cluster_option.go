@@ -0,0 +1,24 @@
+package grpc
+
+import (
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+)
+
+type ClusterOption func(*cluster)
+
+var (
+	defaultClusterOpts = []ClusterOption{}
+)
+
+func WithClient(c grpc.Client) ClusterOption {
+	return func(cl *cluster) {
+		cl.client = c
+	}
+}
+
+func WithRing(r service.Ring) ClusterOption {
+	return func(cl *cluster) {
+		cl.ring = r
+	}
+}
@@ -0,0 +1,246 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+	"github.com/vdaas/vald/apis/grpc/meta"
+	"github.com/vdaas/vald/apis/grpc/payload"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// podClient calls the storage pods served in memory.
+type podClient struct {
+	grpc.Client
+	conns map[string]*ggrpc.ClientConn
+}
+
+func (c *podClient) Do(ctx context.Context, addr string,
+	f func(ctx context.Context, conn *grpc.ClientConn, copts ...grpc.CallOption) (interface{}, error)) (interface{}, error) {
+	conn, ok := c.conns[addr]
+	if !ok {
+		return nil, errors.Errorf("unknown pod %s", addr)
+	}
+	return f(ctx, conn)
+}
+
+// startPods serves a storage server for every pod and returns their HaloDBs.
+func startPods(t *testing.T, pods []string) (*podClient, map[string]*memDB) {
+	t.Helper()
+	c := &podClient{
+		conns: make(map[string]*ggrpc.ClientConn, len(pods)),
+	}
+	dbs := make(map[string]*memDB, len(pods))
+	for _, pod := range pods {
+		db := newMemDB(nil)
+		s := New(WithHaloDB(db))
+		lis := bufconn.Listen(1 << 20)
+		srv := ggrpc.NewServer()
+		meta.RegisterMetaServer(srv, s)
+		extension.RegisterMetaExtensionServer(srv, s.(extension.MetaExtensionServer))
+		go srv.Serve(lis)
+		t.Cleanup(srv.Stop)
+		conn, err := ggrpc.Dial(pod,
+			ggrpc.WithInsecure(),
+			ggrpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+				return lis.Dial()
+			}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			conn.Close()
+		})
+		c.conns[pod] = conn
+		dbs[pod] = db
+	}
+	return c, dbs
+}
+
+func Test_cluster_SetMetas(t *testing.T) {
+	type op struct {
+		set []*payload.Meta_KeyVal
+		del []string
+	}
+	type args struct {
+		ops []op
+	}
+	type want struct {
+		kvs map[string]string
+	}
+	type test struct {
+		name      string
+		args      args
+		want      want
+		checkFunc func(want, *cluster, map[string]*memDB) error
+	}
+	// every entry must be on the owner of its key and every inverse entry on the owner of its value,
+	// the inverse entries kept by the owners of the keys are never read by the router
+	defaultCheckFunc := func(w want, c *cluster, dbs map[string]*memDB) error {
+		for pod, db := range dbs {
+			for k, v := range db.entries() {
+				switch {
+				case strings.HasPrefix(k, "kv:"):
+					key := strings.TrimPrefix(k, "kv:")
+					if c.keyOwner(key) != pod || w.kvs[key] != v {
+						return errors.Errorf("unexpected entry %s=%s on %s", k, v, pod)
+					}
+				case strings.HasPrefix(k, "vk:"):
+					val := strings.TrimPrefix(k, "vk:")
+					if c.valOwner(val) != pod {
+						continue
+					}
+					if cur, ok := w.kvs[v]; !ok || cur != val {
+						return errors.Errorf("unexpected inverse entry %s=%s on %s", k, v, pod)
+					}
+				}
+			}
+		}
+		for key, val := range w.kvs {
+			if v, err := dbs[c.keyOwner(key)].Get("kv:" + key); err != nil || v != val {
+				return errors.Errorf("entry of %s is %s, want %s", key, v, val)
+			}
+			if k, err := dbs[c.valOwner(val)].Get("vk:" + val); err != nil || k != key {
+				return errors.Errorf("inverse entry of %s is %s, want %s", val, k, key)
+			}
+		}
+		return nil
+	}
+	kvs := func(prefix string, n int, val func(i int) string) []*payload.Meta_KeyVal {
+		res := make([]*payload.Meta_KeyVal, 0, n)
+		for i := 0; i < n; i++ {
+			res = append(res, &payload.Meta_KeyVal{
+				Key: prefix + strconv.Itoa(i),
+				Val: val(i),
+			})
+		}
+		return res
+	}
+	tests := []test{
+		{
+			name: "the inverse entries are placed on the owners of the values",
+			args: args{
+				ops: []op{
+					{
+						set: kvs("k", 16, func(i int) string { return "v" + strconv.Itoa(i) }),
+					},
+				},
+			},
+			want: want{
+				kvs: func() map[string]string {
+					m := make(map[string]string)
+					for i := 0; i < 16; i++ {
+						m["k"+strconv.Itoa(i)] = "v" + strconv.Itoa(i)
+					}
+					return m
+				}(),
+			},
+		},
+		{
+			name: "the inverse entries of the previous values are removed",
+			args: args{
+				ops: []op{
+					{
+						set: kvs("k", 16, func(i int) string { return "a" + strconv.Itoa(i) }),
+					},
+					{
+						set: kvs("k", 16, func(i int) string { return "b" + strconv.Itoa(i) }),
+					},
+				},
+			},
+			want: want{
+				kvs: func() map[string]string {
+					m := make(map[string]string)
+					for i := 0; i < 16; i++ {
+						m["k"+strconv.Itoa(i)] = "b" + strconv.Itoa(i)
+					}
+					return m
+				}(),
+			},
+		},
+		{
+			name: "a key set twice in a batch keeps the inverse entry of the last value",
+			args: args{
+				ops: []op{
+					{
+						set: append(
+							kvs("k", 16, func(i int) string { return "a" + strconv.Itoa(i) }),
+							kvs("k", 16, func(i int) string { return "b" + strconv.Itoa(i) })...),
+					},
+				},
+			},
+			want: want{
+				kvs: func() map[string]string {
+					m := make(map[string]string)
+					for i := 0; i < 16; i++ {
+						m["k"+strconv.Itoa(i)] = "b" + strconv.Itoa(i)
+					}
+					return m
+				}(),
+			},
+		},
+		{
+			name: "the inverse entries of the deleted keys are removed",
+			args: args{
+				ops: []op{
+					{
+						set: kvs("k", 16, func(i int) string { return "v" + strconv.Itoa(i) }),
+					},
+					{
+						del: []string{"k0", "k1", "k2", "k3", "k4", "k5", "k6", "k7"},
+					},
+				},
+			},
+			want: want{
+				kvs: func() map[string]string {
+					m := make(map[string]string)
+					for i := 8; i < 16; i++ {
+						m["k"+strconv.Itoa(i)] = "v" + strconv.Itoa(i)
+					}
+					return m
+				}(),
+			},
+		},
+	}
+
+	pods := []string{"pod-0", "pod-1", "pod-2"}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			ctx := context.Background()
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			client, dbs := startPods(tt, pods)
+			ring, err := service.NewRing(pods, 16)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			c := NewCluster(WithClient(client), WithRing(ring)).(*cluster)
+
+			for _, op := range test.args.ops {
+				if len(op.set) != 0 {
+					if _, err := c.SetMetas(ctx, &payload.Meta_KeyVals{Kvs: op.set}); err != nil {
+						tt.Fatal(err)
+					}
+				}
+				if len(op.del) != 0 {
+					if _, err := c.DeleteMetas(ctx, &payload.Meta_Keys{Keys: op.del}); err != nil {
+						tt.Fatal(err)
+					}
+				}
+			}
+			if err := checkFunc(test.want, c, dbs); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
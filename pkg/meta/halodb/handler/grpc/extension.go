@@ -0,0 +1,180 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rinx/vald-meta-halodb/internal/info"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc/status"
+	"github.com/rinx/vald-meta-halodb/internal/observability/trace"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/payload"
+)
+
+func (s *server) SetMetaIfAbsent(ctx context.Context, kv *payload.Meta_KeyVal) (_ *payload.Empty, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.SetMetaIfAbsent")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	ttl, err := ttlFromContext(ctx)
+	if err != nil || ttl < 0 || (ttl > 0 && s.ttl == nil) {
+		log.Warnf("[SetMetaIfAbsent]\tinvalid ttl\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(fmt.Sprintf("invalid ttl %s", ttl)))
+		}
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("SetMetaIfAbsent API haloDB key %s val %s invalid ttl", kv.GetKey(), kv.GetVal()), err, info.Get())
+	}
+	ver, err := s.setMeta(kv.GetKey(), kv.GetVal(), ttl, func(cur string, found bool) error {
+		if found {
+			return errKeyAlreadyExists
+		}
+		return nil
+	})
+	switch err {
+	case nil:
+		setVersionHeader(ctx, ver)
+		return new(payload.Empty), nil
+	case errKeyAlreadyExists, errValueAlreadyExists:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeAlreadyExists(err.Error()))
+		}
+		return nil, status.WrapWithAlreadyExists(fmt.Sprintf("SetMetaIfAbsent API haloDB key %s val %s already exists", kv.GetKey(), kv.GetVal()), err, info.Get())
+	case errInvalidJSON:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(err.Error()))
+		}
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("SetMetaIfAbsent API haloDB key %s val %s is not a valid JSON", kv.GetKey(), kv.GetVal()), err, info.Get())
+	case errQuotaExceeded:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeResourceExhausted(err.Error()))
+		}
+		return nil, status.WrapWithResourceExhausted(fmt.Sprintf("SetMetaIfAbsent API haloDB key %s val %s namespace quota exceeded", kv.GetKey(), kv.GetVal()), err, info.Get())
+	}
+	log.Errorf("[SetMetaIfAbsent]\tunknown error\t%+v", err)
+	if span != nil {
+		span.SetStatus(trace.StatusCodeInternal(err.Error()))
+	}
+	return nil, status.WrapWithInternal(fmt.Sprintf("SetMetaIfAbsent API haloDB key %s val %s failed to store", kv.GetKey(), kv.GetVal()), err, info.Get())
+}
+
+func (s *server) CompareAndSetMeta(ctx context.Context, req *extension.CompareAndSetRequest) (_ *payload.Empty, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.CompareAndSetMeta")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	ttl, err := ttlFromContext(ctx)
+	if err != nil || ttl < 0 || (ttl > 0 && s.ttl == nil) {
+		log.Warnf("[CompareAndSetMeta]\tinvalid ttl\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(fmt.Sprintf("invalid ttl %s", ttl)))
+		}
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("CompareAndSetMeta API haloDB key %s val %s invalid ttl", req.GetKey(), req.GetVal()), err, info.Get())
+	}
+	ver, err := s.setMeta(req.GetKey(), req.GetVal(), ttl, func(cur string, found bool) error {
+		if !found || cur != req.GetExpected() {
+			return errConditionFailed
+		}
+		return nil
+	})
+	switch err {
+	case nil:
+		setVersionHeader(ctx, ver)
+		return new(payload.Empty), nil
+	case errConditionFailed:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeFailedPrecondition(err.Error()))
+		}
+		return nil, status.WrapWithFailedPrecondition(fmt.Sprintf("CompareAndSetMeta API haloDB key %s is not %s", req.GetKey(), req.GetExpected()), err, info.Get())
+	case errValueAlreadyExists:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeAlreadyExists(err.Error()))
+		}
+		return nil, status.WrapWithAlreadyExists(fmt.Sprintf("CompareAndSetMeta API haloDB key %s val %s already exists", req.GetKey(), req.GetVal()), err, info.Get())
+	case errInvalidJSON:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(err.Error()))
+		}
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("CompareAndSetMeta API haloDB key %s val %s is not a valid JSON", req.GetKey(), req.GetVal()), err, info.Get())
+	case errQuotaExceeded:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeResourceExhausted(err.Error()))
+		}
+		return nil, status.WrapWithResourceExhausted(fmt.Sprintf("CompareAndSetMeta API haloDB key %s val %s namespace quota exceeded", req.GetKey(), req.GetVal()), err, info.Get())
+	}
+	log.Errorf("[CompareAndSetMeta]\tunknown error\t%+v", err)
+	if span != nil {
+		span.SetStatus(trace.StatusCodeInternal(err.Error()))
+	}
+	return nil, status.WrapWithInternal(fmt.Sprintf("CompareAndSetMeta API haloDB key %s val %s failed to store", req.GetKey(), req.GetVal()), err, info.Get())
+}
+
+func (s *server) GetMetaInverseAll(ctx context.Context, val *payload.Meta_Val) (*payload.Meta_Keys, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.GetMetaInverseAll")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	keys, err := s.inverseKeys(val.GetVal())
+	if err != nil {
+		log.Warnf("[GetMetaInverseAll]\tval %s not found", val.GetVal())
+		if span != nil {
+			span.SetStatus(trace.StatusCodeNotFound(err.Error()))
+		}
+		return nil, status.WrapWithNotFound(fmt.Sprintf("GetMetaInverseAll API haloDB val %s not found", val.GetVal()), err, info.Get())
+	}
+	return &payload.Meta_Keys{
+		Keys: keys,
+	}, nil
+}
+
+func (s *server) DeleteMetaInverseAll(ctx context.Context, val *payload.Meta_Val) (*payload.Meta_Keys, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.DeleteMetaInverseAll")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	keys, err := s.deleteVal(val.GetVal())
+	if err != nil {
+		log.Errorf("[DeleteMetaInverseAll]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeUnknown(err.Error()))
+		}
+		return nil, status.WrapWithUnknown(fmt.Sprintf("DeleteMetaInverseAll API val %s unknown error occurred", val.GetVal()), err, info.Get())
+	}
+	return &payload.Meta_Keys{
+		Keys: keys,
+	}, nil
+}
+
+func (s *server) QueryMeta(ctx context.Context, req *extension.IndexQueryRequest) (*payload.Meta_Keys, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.QueryMeta")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	if !s.indexed(req.GetField()) {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(fmt.Sprintf("field %s is not indexed", req.GetField())))
+		}
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("QueryMeta API haloDB field %s is not indexed", req.GetField()), nil, info.Get())
+	}
+	keys, err := s.queryIndex(req.GetField(), req.GetValue())
+	if err != nil {
+		log.Warnf("[QueryMeta]\t%s=%s not found", req.GetField(), req.GetValue())
+		if span != nil {
+			span.SetStatus(trace.StatusCodeNotFound(err.Error()))
+		}
+		return nil, status.WrapWithNotFound(fmt.Sprintf("QueryMeta API haloDB %s=%s not found", req.GetField(), req.GetValue()), err, info.Get())
+	}
+	return &payload.Meta_Keys{
+		Keys: keys,
+	}, nil
+}
@@ -3,6 +3,8 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/rinx/vald-meta-halodb/internal/info"
 	"github.com/rinx/vald-meta-halodb/internal/log"
@@ -15,6 +17,38 @@ import (
 
 type server struct {
 	haloDB service.HaloDB
+	ttl    ttlWriter
+	policy UniquenessPolicy
+	// jsonValues rejects the values which are not valid JSON documents
+	jsonValues  bool
+	indexFields []string
+	// namespaces are the allowed namespaces, ns and quota are set on the server of each namespace
+	namespaces []namespaceConfig
+	ns         service.Namespace
+	quota      int64
+	// tenant is set on the server of a namespace with its own data directory
+	tenant service.Tenant
+	// historySize is the number of versions kept for each key, the history is disabled when it is not positive
+	historySize int
+	// versioning gives every entry a version increased by each write
+	versioning bool
+	// trash keeps the deleted entries until they are purged by a TrashSweeper,
+	// trashPending is set while the trash may have entries
+	trash        bool
+	trashPending bool
+	// mu is held exclusively by the operations over many entries,
+	// the writes of an entry hold it shared with the locks of the entries they read before writing
+	mu    sync.RWMutex
+	locks keyLocks
+	// slotLocks are the locks of the slot entries, countMu and trashMu guard the entries shared by all the keys
+	slotLocks keyLocks
+	countMu   sync.Mutex
+	trashMu   sync.Mutex
+}
+
+// ttlWriter is a service.TTL or a service.Namespace on it.
+type ttlWriter interface {
+	PutWithTTL(d time.Duration, kvs map[string]string) error
 }
 
 func New(opts ...Option) meta.MetaServer {
@@ -23,6 +57,9 @@ func New(opts ...Option) meta.MetaServer {
 	for _, opt := range append(defaultOpts, opts...) {
 		opt(s)
 	}
+	if len(s.namespaces) != 0 {
+		return newNamespaces(s)
+	}
 	return s
 }
 
@@ -42,7 +79,7 @@ func (s *server) GetMeta(ctx context.Context, key *payload.Meta_Key) (*payload.M
 		}
 	}()
 
-	val, err := s.haloDB.Get(s.kvKey(key.GetKey()))
+	val, ver, err := s.getMeta(key.GetKey())
 	if err != nil {
 		log.Warnf("[GetMeta]\tkey %s not found", key.GetKey())
 		if span != nil {
@@ -50,6 +87,7 @@ func (s *server) GetMeta(ctx context.Context, key *payload.Meta_Key) (*payload.M
 		}
 		return nil, status.WrapWithNotFound(fmt.Sprintf("GetMeta API haloDB key %s not found", key.GetKey()), err, info.Get())
 	}
+	setVersionHeader(ctx, ver)
 	return &payload.Meta_Val{
 		Val: val,
 	}, nil
@@ -84,7 +122,7 @@ func (s *server) GetMetaInverse(ctx context.Context, val *payload.Meta_Val) (*pa
 			span.End()
 		}
 	}()
-	key, err := s.haloDB.Get(s.vkKey(val.GetVal()))
+	key, err := s.inverseKey(val.GetVal())
 	if err != nil {
 		log.Warnf("[GetMetaInverse]\tval %s not found", val.GetVal())
 		if span != nil {
@@ -106,7 +144,7 @@ func (s *server) GetMetasInverse(ctx context.Context, vals *payload.Meta_Vals) (
 	}()
 	mk = new(payload.Meta_Keys)
 	for _, v := range vals.GetVals() {
-		k, err := s.haloDB.Get(s.vkKey(v))
+		k, err := s.inverseKey(v)
 		if err != nil {
 			log.Warnf("[GetMetasInverse]\tvals %#v not found", vals.GetVals())
 			if span != nil {
@@ -126,15 +164,60 @@ func (s *server) SetMeta(ctx context.Context, kv *payload.Meta_KeyVal) (_ *paylo
 			span.End()
 		}
 	}()
-	err = s.haloDB.Put(s.kvKey(kv.GetKey()), kv.GetVal())
-	if err != nil {
-		log.Errorf("[SetMeta]\tunknown error\t%+v", err)
+	ttl, err := ttlFromContext(ctx)
+	if err != nil || ttl < 0 {
+		log.Warnf("[SetMeta]\tinvalid ttl\t%+v", err)
 		if span != nil {
-			span.SetStatus(trace.StatusCodeInternal(err.Error()))
+			span.SetStatus(trace.StatusCodeInvalidArgument(fmt.Sprintf("invalid ttl %s", ttl)))
 		}
-		return nil, status.WrapWithInternal(fmt.Sprintf("SetMeta API haloDB key %s val %s failed to store", kv.GetKey(), kv.GetVal()), err, info.Get())
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("SetMeta API haloDB key %s val %s invalid ttl", kv.GetKey(), kv.GetVal()), err, info.Get())
+	}
+	if ttl > 0 && s.ttl == nil {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument("ttl is not enabled"))
+		}
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("SetMeta API haloDB key %s val %s ttl is not enabled", kv.GetKey(), kv.GetVal()), nil, info.Get())
+	}
+	expected, ok, err := expectedVersionFromContext(ctx)
+	if err != nil || (ok && !s.versioning) {
+		if err == nil {
+			err = errVersioningDisabled
+		}
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(err.Error()))
+		}
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("SetMeta API haloDB key %s val %s invalid expected version", kv.GetKey(), kv.GetVal()), err, info.Get())
+	}
+	var cond func(cur string, found bool) error
+	if ok {
+		cond = s.versionCond(kv.GetKey(), expected)
+	}
+	ver, err := s.setMeta(kv.GetKey(), kv.GetVal(), ttl, cond)
+	if err == errVersionMismatch {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeFailedPrecondition(err.Error()))
+		}
+		return nil, status.WrapWithFailedPrecondition(fmt.Sprintf("SetMeta API haloDB key %s version is not %d", kv.GetKey(), expected), err, info.Get())
+	}
+	if err == errInvalidJSON {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(err.Error()))
+		}
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("SetMeta API haloDB key %s val %s is not a valid JSON", kv.GetKey(), kv.GetVal()), err, info.Get())
+	}
+	if err == errQuotaExceeded {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeResourceExhausted(err.Error()))
+		}
+		return nil, status.WrapWithResourceExhausted(fmt.Sprintf("SetMeta API haloDB key %s val %s namespace quota exceeded", kv.GetKey(), kv.GetVal()), err, info.Get())
+	}
+	if err == errValueAlreadyExists {
+		log.Warnf("[SetMeta]\tval %s already exists", kv.GetVal())
+		if span != nil {
+			span.SetStatus(trace.StatusCodeAlreadyExists(err.Error()))
+		}
+		return nil, status.WrapWithAlreadyExists(fmt.Sprintf("SetMeta API haloDB key %s val %s already exists", kv.GetKey(), kv.GetVal()), err, info.Get())
 	}
-	err = s.haloDB.Put(s.vkKey(kv.GetVal()), kv.GetKey())
 	if err != nil {
 		log.Errorf("[SetMeta]\tunknown error\t%+v", err)
 		if span != nil {
@@ -142,6 +225,7 @@ func (s *server) SetMeta(ctx context.Context, kv *payload.Meta_KeyVal) (_ *paylo
 		}
 		return nil, status.WrapWithInternal(fmt.Sprintf("SetMeta API haloDB key %s val %s failed to store", kv.GetKey(), kv.GetVal()), err, info.Get())
 	}
+	setVersionHeader(ctx, ver)
 	return new(payload.Empty), nil
 }
 
@@ -152,8 +236,17 @@ func (s *server) SetMetas(ctx context.Context, kvs *payload.Meta_KeyVals) (_ *pa
 			span.End()
 		}
 	}()
+	if _, ok := metadataValue(ctx, ExpectedVersionMetadataKey); ok {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument("expected version is not supported"))
+		}
+		return nil, status.WrapWithInvalidArgument("SetMetas API haloDB expected version is only supported by SetMeta", nil, info.Get())
+	}
 	for _, kv := range kvs.GetKvs() {
 		_, err = s.SetMeta(ctx, kv)
+		if isStatus(err) {
+			return nil, err
+		}
 		if err != nil {
 			log.Errorf("[SetMetas]\tunknown error\t%+v", err)
 			if span != nil {
@@ -172,6 +265,35 @@ func (s *server) DeleteMeta(ctx context.Context, key *payload.Meta_Key) (*payloa
 			span.End()
 		}
 	}()
+	expected, ok, err := expectedVersionFromContext(ctx)
+	if err != nil || (ok && !s.versioning) {
+		if err == nil {
+			err = errVersioningDisabled
+		}
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(err.Error()))
+		}
+		return nil, status.WrapWithInvalidArgument(fmt.Sprintf("DeleteMeta API haloDB key %s invalid expected version", key.GetKey()), err, info.Get())
+	}
+	if ok {
+		val, err := s.deleteKeyIfVersion(key.GetKey(), expected)
+		if err == errVersionMismatch {
+			if span != nil {
+				span.SetStatus(trace.StatusCodeFailedPrecondition(err.Error()))
+			}
+			return nil, status.WrapWithFailedPrecondition(fmt.Sprintf("DeleteMeta API haloDB key %s version is not %d", key.GetKey(), expected), err, info.Get())
+		}
+		if err != nil {
+			log.Errorf("[DeleteMeta]\tunknown error\t%+v", err)
+			if span != nil {
+				span.SetStatus(trace.StatusCodeUnknown(err.Error()))
+			}
+			return nil, status.WrapWithUnknown(fmt.Sprintf("DeleteMeta API haloDB unknown error occurred key %s", key.GetKey()), err, info.Get())
+		}
+		return &payload.Meta_Val{
+			Val: val,
+		}, nil
+	}
 	val, err := s.GetMeta(ctx, key)
 	if err != nil {
 		log.Errorf("[DeleteMeta]\tunknown error\t%+v", err)
@@ -180,7 +302,7 @@ func (s *server) DeleteMeta(ctx context.Context, key *payload.Meta_Key) (*payloa
 		}
 		return nil, status.WrapWithUnknown(fmt.Sprintf("DeleteMeta API haloDB unknown error occurred key %s", key.GetKey()), err, info.Get())
 	}
-	err = s.haloDB.Delete(s.kvKey(key.GetKey()))
+	err = s.deleteKey(key.GetKey(), val.GetVal())
 	if err != nil {
 		log.Errorf("[DeleteMeta]\tunknown error\t%+v", err)
 		if span != nil {
@@ -198,7 +320,16 @@ func (s *server) DeleteMetas(ctx context.Context, keys *payload.Meta_Keys) (mv *
 			span.End()
 		}
 	}()
+	if _, ok := metadataValue(ctx, ExpectedVersionMetadataKey); ok {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument("expected version is not supported"))
+		}
+		return nil, status.WrapWithInvalidArgument("DeleteMetas API haloDB expected version is only supported by DeleteMeta", nil, info.Get())
+	}
 	mv, err = s.GetMetas(ctx, keys)
+	if isStatus(err) {
+		return mv, err
+	}
 	if err != nil {
 		log.Errorf("[DeleteMetas]\tunknown error\t%+v", err)
 		if span != nil {
@@ -206,8 +337,8 @@ func (s *server) DeleteMetas(ctx context.Context, keys *payload.Meta_Keys) (mv *
 		}
 		return mv, status.WrapWithUnknown(fmt.Sprintf("DeleteMetas API haloDB entry keys %#v unknown error occurred", keys.GetKeys()), err, info.Get())
 	}
-	for _, k := range keys.GetKeys() {
-		err = s.haloDB.Delete(s.kvKey(k))
+	for i, k := range keys.GetKeys() {
+		err = s.deleteKey(k, mv.GetVals()[i])
 		if err != nil {
 			log.Errorf("[DeleteMetas]\tunknown error\t%+v", err)
 			if span != nil {
@@ -234,7 +365,7 @@ func (s *server) DeleteMetaInverse(ctx context.Context, val *payload.Meta_Val) (
 		}
 		return nil, status.WrapWithUnknown(fmt.Sprintf("DeleteMetaInverse API val %s unknown error occurred", val.GetVal()), err, info.Get())
 	}
-	err = s.haloDB.Delete(s.vkKey(val.GetVal()))
+	_, err = s.deleteVal(val.GetVal())
 	if err != nil {
 		log.Errorf("[DeleteMetaInverse]\tunknown error\t%+v", err)
 		if span != nil {
@@ -253,6 +384,9 @@ func (s *server) DeleteMetasInverse(ctx context.Context, vals *payload.Meta_Vals
 		}
 	}()
 	mk, err = s.GetMetasInverse(ctx, vals)
+	if isStatus(err) {
+		return mk, err
+	}
 	if err != nil {
 		log.Errorf("[DeleteMetasInverse]\tunknown error\t%+v", err)
 		if span != nil {
@@ -261,7 +395,7 @@ func (s *server) DeleteMetasInverse(ctx context.Context, vals *payload.Meta_Vals
 		return mk, status.WrapWithUnknown(fmt.Sprintf("DeleteMetasInverse API vals %#v unknown error occurred", vals.GetVals()), err, info.Get())
 	}
 	for _, v := range vals.GetVals() {
-		err = s.haloDB.Delete(s.vkKey(v))
+		_, err = s.deleteVal(v)
 		if err != nil {
 			log.Errorf("[DeleteMetasInverse]\tunknown error\t%+v", err)
 			if span != nil {
@@ -272,3 +406,26 @@ func (s *server) DeleteMetasInverse(ctx context.Context, vals *payload.Meta_Vals
 	}
 	return mk, nil
 }
+
+// isStatus reports whether err is a status returned by an entry API, the batch APIs return it as is.
+func isStatus(err error) bool {
+	return err != nil && status.FromError(err) != nil
+}
+
+// getMeta returns the value of key with its version, the version is 0 without versioning.
+func (s *server) getMeta(key string) (string, uint64, error) {
+	if !s.versioning {
+		val, err := s.haloDB.Get(s.kvKey(key))
+		return val, 0, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	defer s.locks.rlock(s.kvKey(key))()
+
+	val, err := s.haloDB.Get(s.kvKey(key))
+	if err != nil {
+		return "", 0, err
+	}
+	return val, s.entryVersion(key), nil
+}
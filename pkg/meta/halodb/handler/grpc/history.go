@@ -0,0 +1,183 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/info"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc/status"
+	"github.com/rinx/vald-meta-halodb/internal/observability/trace"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/payload"
+)
+
+var (
+	errHistoryDisabled = errors.New("history is not enabled")
+	errNoSuchVersion   = errors.New("version not found")
+)
+
+// version is a value the key held, Timestamp is when it was set in unix nanoseconds.
+type version struct {
+	Version   uint64 `json:"version"`
+	Val       string `json:"val"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// hsKey holds the versions of the key, oldest first.
+// The history is kept after the key is deleted, so the key can be rolled back.
+func (s *server) hsKey(key string) string {
+	return "hs:" + key
+}
+
+func (s *server) versions(key string) ([]version, error) {
+	raw, err := s.haloDB.Get(s.hsKey(key))
+	if err != nil {
+		return nil, err
+	}
+	var vs []version
+	err = json.Unmarshal([]byte(raw), &vs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid history of %s", key)
+	}
+	return vs, nil
+}
+
+// addVersion records val as the version ver of key and drops the versions over the history size.
+// ver is the entry version with versioning, the next one of the history is used when it is 0.
+// It must be called with the entry locked, it is a no-op when the history is disabled.
+func (s *server) addVersion(key, val string, ver uint64) {
+	if s.historySize <= 0 {
+		return
+	}
+	vs, err := s.versions(key)
+	if err != nil {
+		vs = nil
+	}
+	next := ver
+	if next == 0 {
+		next = 1
+		if len(vs) != 0 {
+			next = vs[len(vs)-1].Version + 1
+		}
+	}
+	vs = append(vs, version{
+		Version:   next,
+		Val:       val,
+		Timestamp: time.Now().UnixNano(),
+	})
+	if len(vs) > s.historySize {
+		vs = vs[len(vs)-s.historySize:]
+	}
+	raw, err := json.Marshal(vs)
+	if err == nil {
+		err = s.haloDB.Put(s.hsKey(key), string(raw))
+	}
+	if err != nil {
+		log.Warnf("[History]\tfailed to record the version %d of %s\t%+v", next, key, err)
+	}
+}
+
+// rollback sets the value of the version to the key as a new version.
+// The inverse and JSON index entries follow the uniqueness policy like SetMeta.
+func (s *server) rollback(key string, v uint64) error {
+	if s.historySize <= 0 {
+		return errHistoryDisabled
+	}
+	s.mu.RLock()
+	unlock := s.locks.rlock(s.kvKey(key))
+	vs, err := s.versions(key)
+	unlock()
+	s.mu.RUnlock()
+	if err != nil {
+		return errNoSuchVersion
+	}
+	for _, ver := range vs {
+		if ver.Version == v {
+			_, err = s.setMeta(key, ver.Val, 0, nil)
+			return err
+		}
+	}
+	return errNoSuchVersion
+}
+
+func (s *server) GetMetaHistory(ctx context.Context, key *payload.Meta_Key) (*extension.MetaHistory, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.GetMetaHistory")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	if s.historySize <= 0 {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeFailedPrecondition(errHistoryDisabled.Error()))
+		}
+		return nil, status.WrapWithFailedPrecondition(fmt.Sprintf("GetMetaHistory API haloDB key %s history is not enabled", key.GetKey()), errHistoryDisabled, info.Get())
+	}
+	s.mu.RLock()
+	unlock := s.locks.rlock(s.kvKey(key.GetKey()))
+	vs, err := s.versions(key.GetKey())
+	unlock()
+	s.mu.RUnlock()
+	if err != nil {
+		log.Warnf("[GetMetaHistory]\tnot found\t%v\t%+v", key.GetKey(), err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeNotFound(err.Error()))
+		}
+		return nil, status.WrapWithNotFound(fmt.Sprintf("GetMetaHistory API haloDB key %s not found", key.GetKey()), err, info.Get())
+	}
+	res := &extension.MetaHistory{
+		Key:      key.GetKey(),
+		Versions: make([]*extension.MetaVersion, 0, len(vs)),
+	}
+	for _, v := range vs {
+		res.Versions = append(res.Versions, &extension.MetaVersion{
+			Version:   v.Version,
+			Val:       v.Val,
+			Timestamp: v.Timestamp,
+		})
+	}
+	return res, nil
+}
+
+func (s *server) RollbackMeta(ctx context.Context, req *extension.RollbackRequest) (*payload.Empty, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.RollbackMeta")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	err := s.rollback(req.GetKey(), req.GetVersion())
+	switch err {
+	case nil:
+		return new(payload.Empty), nil
+	case errHistoryDisabled:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeFailedPrecondition(err.Error()))
+		}
+		return nil, status.WrapWithFailedPrecondition(fmt.Sprintf("RollbackMeta API haloDB key %s history is not enabled", req.GetKey()), err, info.Get())
+	case errNoSuchVersion:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeNotFound(err.Error()))
+		}
+		return nil, status.WrapWithNotFound(fmt.Sprintf("RollbackMeta API haloDB key %s version %d not found", req.GetKey(), req.GetVersion()), err, info.Get())
+	case errValueAlreadyExists:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeAlreadyExists(err.Error()))
+		}
+		return nil, status.WrapWithAlreadyExists(fmt.Sprintf("RollbackMeta API haloDB key %s version %d value already exists", req.GetKey(), req.GetVersion()), err, info.Get())
+	case errQuotaExceeded:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeResourceExhausted(err.Error()))
+		}
+		return nil, status.WrapWithResourceExhausted(fmt.Sprintf("RollbackMeta API haloDB key %s version %d namespace quota exceeded", req.GetKey(), req.GetVersion()), err, info.Get())
+	}
+	log.Errorf("[RollbackMeta]\tunknown error\t%+v", err)
+	if span != nil {
+		span.SetStatus(trace.StatusCodeInternal(err.Error()))
+	}
+	return nil, status.WrapWithInternal(fmt.Sprintf("RollbackMeta API haloDB key %s version %d failed to store", req.GetKey(), req.GetVersion()), err, info.Get())
+}
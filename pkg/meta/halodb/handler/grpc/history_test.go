@@ -0,0 +1,240 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/meta"
+	"github.com/vdaas/vald/apis/grpc/payload"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// hsOp is an operation of a history test, op is one of set, del, get, inv, history and rollback.
+type hsOp struct {
+	op      string
+	key     string
+	val     string
+	version uint64
+}
+
+// hsResult is the code of an operation, with the value or key it read,
+// or the versions returned by history as version:value joined by ','.
+type hsResult struct {
+	code codes.Code
+	val  string
+}
+
+func runHistoryOps(s meta.MetaServer, ops []hsOp) []hsResult {
+	ext := s.(extension.MetaExtensionServer)
+	ctx := context.Background()
+	res := make([]hsResult, 0, len(ops))
+	for _, op := range ops {
+		var (
+			r   hsResult
+			err error
+		)
+		switch op.op {
+		case "set":
+			_, err = s.SetMeta(ctx, &payload.Meta_KeyVal{Key: op.key, Val: op.val})
+		case "del":
+			_, err = s.DeleteMeta(ctx, &payload.Meta_Key{Key: op.key})
+		case "get":
+			var v *payload.Meta_Val
+			v, err = s.GetMeta(ctx, &payload.Meta_Key{Key: op.key})
+			r.val = v.GetVal()
+		case "inv":
+			var k *payload.Meta_Key
+			k, err = s.GetMetaInverse(ctx, &payload.Meta_Val{Val: op.val})
+			r.val = k.GetKey()
+		case "history":
+			var h *extension.MetaHistory
+			h, err = ext.GetMetaHistory(ctx, &payload.Meta_Key{Key: op.key})
+			vs := make([]string, 0, len(h.GetVersions()))
+			for _, v := range h.GetVersions() {
+				vs = append(vs, strconv.FormatUint(v.GetVersion(), 10)+":"+v.GetVal())
+			}
+			r.val = strings.Join(vs, ",")
+		case "rollback":
+			_, err = ext.RollbackMeta(ctx, &extension.RollbackRequest{Key: op.key, Version: op.version})
+		}
+		r.code = status.Code(err)
+		res = append(res, r)
+	}
+	return res
+}
+
+func Test_server_RollbackMeta(t *testing.T) {
+	type args struct {
+		ops []hsOp
+	}
+	type fields struct {
+		historySize int
+		policy      UniquenessPolicy
+	}
+	type want struct {
+		results []hsResult
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, []hsResult) error
+	}
+	defaultCheckFunc := func(w want, res []hsResult) error {
+		if !reflect.DeepEqual(res, w.results) {
+			return errors.Errorf("got results = %v, want %v", res, w.results)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "only the last history_size versions are kept",
+			args: args{
+				ops: []hsOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "set", key: "a", val: "z"},
+					{op: "history", key: "a"},
+					{op: "rollback", key: "a", version: 1},
+				},
+			},
+			fields: fields{
+				historySize: 2,
+			},
+			want: want{
+				results: []hsResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "2:y,3:z"},
+					{code: codes.NotFound},
+				},
+			},
+		},
+		{
+			name: "a deleted key is rolled back with its inverse entry as a new version",
+			args: args{
+				ops: []hsOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "del", key: "a"},
+					{op: "get", key: "a"},
+					{op: "rollback", key: "a", version: 1},
+					{op: "get", key: "a"},
+					{op: "inv", val: "x"},
+					{op: "history", key: "a"},
+				},
+			},
+			fields: fields{
+				historySize: 3,
+			},
+			want: want{
+				results: []hsResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.NotFound},
+					{code: codes.OK},
+					{code: codes.OK, val: "x"},
+					{code: codes.OK, val: "a"},
+					{code: codes.OK, val: "1:x,2:y,3:x"},
+				},
+			},
+		},
+		{
+			name: "a rollback to a value owned by another key fails under the reject policy",
+			args: args{
+				ops: []hsOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "set", key: "b", val: "x"},
+					{op: "rollback", key: "a", version: 1},
+					{op: "get", key: "a"},
+					{op: "inv", val: "x"},
+				},
+			},
+			fields: fields{
+				historySize: 3,
+				policy:      RejectPolicy,
+			},
+			want: want{
+				results: []hsResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.AlreadyExists},
+					{code: codes.OK, val: "y"},
+					{code: codes.OK, val: "b"},
+				},
+			},
+		},
+		{
+			name: "a rollback to a value owned by another key deletes it under the overwrite policy",
+			args: args{
+				ops: []hsOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "set", key: "b", val: "x"},
+					{op: "rollback", key: "a", version: 1},
+					{op: "get", key: "a"},
+					{op: "get", key: "b"},
+					{op: "inv", val: "x"},
+				},
+			},
+			fields: fields{
+				historySize: 3,
+				policy:      OverwritePolicy,
+			},
+			want: want{
+				results: []hsResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "x"},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "a"},
+				},
+			},
+		},
+		{
+			name: "the history APIs fail when the history is disabled",
+			args: args{
+				ops: []hsOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "history", key: "a"},
+					{op: "rollback", key: "a", version: 1},
+				},
+			},
+			want: want{
+				results: []hsResult{
+					{code: codes.OK},
+					{code: codes.FailedPrecondition},
+					{code: codes.FailedPrecondition},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			s := New(WithHaloDB(newMemDB(nil)), WithHistorySize(test.fields.historySize), WithUniquenessPolicy(test.fields.policy))
+
+			res := runHistoryOps(s, test.args.ops)
+			if err := checkFunc(test.want, res); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
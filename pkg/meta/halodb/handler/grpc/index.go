@@ -0,0 +1,148 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+)
+
+// ixKey returns the index entry of the field value, its keys are stored in slots.
+// The field and the value are length prefixed, so they can contain ':'.
+func (s *server) ixKey(field, value string) string {
+	return "ix:" + strconv.Itoa(len(field)) + ":" + field + ":" + strconv.Itoa(len(value)) + ":" + value
+}
+
+// fieldValues returns the indexable values of the dot separated field path in the JSON document.
+// Strings, numbers and booleans are indexed, arrays are indexed by each element.
+func fieldValues(doc, field string) []string {
+	d := json.NewDecoder(bytes.NewReader([]byte(doc)))
+	d.UseNumber()
+	var v interface{}
+	if d.Decode(&v) != nil {
+		return nil
+	}
+	for _, name := range strings.Split(field, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v, ok = m[name]
+		if !ok {
+			return nil
+		}
+	}
+	if vs, ok := v.([]interface{}); ok {
+		res := make([]string, 0, len(vs))
+		for _, v := range vs {
+			if s, ok := scalar(v); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	if s, ok := scalar(v); ok {
+		return []string{s}
+	}
+	return nil
+}
+
+func scalar(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case json.Number:
+		return x.String(), true
+	case bool:
+		return strconv.FormatBool(x), true
+	}
+	return "", false
+}
+
+func contains(vs []string, v string) bool {
+	for _, x := range vs {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// updateIndexes moves key from the index entries of the previous document to the ones of the new document.
+// Empty documents have no index entry. It must be called with the entry locked.
+func (s *server) updateIndexes(key, prev, doc string) {
+	for _, field := range s.indexFields {
+		var olds, news []string
+		if len(prev) != 0 {
+			olds = fieldValues(prev, field)
+		}
+		if len(doc) != 0 {
+			news = fieldValues(doc, field)
+		}
+		for _, v := range olds {
+			if contains(news, v) {
+				continue
+			}
+			err := s.removeSlotKey(s.ixKey(field, v), key)
+			if err != nil {
+				log.Warnf("[Index]\tfailed to remove %s from the index %s=%s\t%+v", key, field, v, err)
+			}
+		}
+		for _, v := range news {
+			if contains(olds, v) {
+				continue
+			}
+			err := s.addSlotKey(s.ixKey(field, v), key)
+			if err != nil {
+				log.Warnf("[Index]\tfailed to add %s to the index %s=%s\t%+v", key, field, v, err)
+			}
+		}
+	}
+}
+
+func (s *server) indexed(field string) bool {
+	return contains(s.indexFields, field)
+}
+
+// queryIndex returns the keys whose document has the value in the field, in no particular order.
+// The keys whose entry expired after it was indexed are skipped and removed from the index entry,
+// because the TTL sweeper deletes the entries without knowing their indexed fields.
+func (s *server) queryIndex(field, value string) ([]string, error) {
+	keys := s.slotKeys(s.ixKey(field, value))
+
+	res := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if s.hasFieldValue(key, field, value) {
+			res = append(res, key)
+			continue
+		}
+		s.removeStaleIndexKey(key, field, value)
+	}
+	if len(res) == 0 {
+		return nil, errors.Errorf("failed to get %s", s.ixKey(field, value))
+	}
+	return res, nil
+}
+
+func (s *server) hasFieldValue(key, field, value string) bool {
+	doc, err := s.haloDB.Get(s.kvKey(key))
+	return err == nil && contains(fieldValues(doc, field), value)
+}
+
+// removeStaleIndexKey removes key from the index entry of the field value unless it has been set to it again.
+func (s *server) removeStaleIndexKey(key, field, value string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	defer s.locks.lock(s.kvKey(key))()
+
+	if s.hasFieldValue(key, field, value) {
+		return
+	}
+	err := s.removeSlotKey(s.ixKey(field, value), key)
+	if err != nil {
+		log.Warnf("[Index]\tfailed to remove the stale key %s from the index %s=%s\t%+v", key, field, value, err)
+	}
+}
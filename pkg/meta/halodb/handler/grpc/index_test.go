@@ -0,0 +1,265 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/meta"
+	"github.com/vdaas/vald/apis/grpc/payload"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ixOp is an operation of an index test, op is one of set, del, expire and query.
+// expire deletes the entry from HaloDB like the TTL sweeper, query looks up key=val.
+type ixOp struct {
+	op  string
+	key string
+	val string
+}
+
+// ixResult is the code of an operation, with the sorted keys, joined by ',', returned by query.
+type ixResult struct {
+	code codes.Code
+	val  string
+}
+
+func runIndexOps(s meta.MetaServer, db *memDB, ops []ixOp) []ixResult {
+	ext := s.(extension.MetaExtensionServer)
+	ctx := context.Background()
+	res := make([]ixResult, 0, len(ops))
+	for _, op := range ops {
+		var (
+			r   ixResult
+			err error
+		)
+		switch op.op {
+		case "set":
+			_, err = s.SetMeta(ctx, &payload.Meta_KeyVal{Key: op.key, Val: op.val})
+		case "del":
+			_, err = s.DeleteMeta(ctx, &payload.Meta_Key{Key: op.key})
+		case "expire":
+			err = db.Delete("kv:" + op.key)
+		case "query":
+			var ks *payload.Meta_Keys
+			ks, err = ext.QueryMeta(ctx, &extension.IndexQueryRequest{Field: op.key, Value: op.val})
+			keys := append([]string(nil), ks.GetKeys()...)
+			sort.Strings(keys)
+			r.val = strings.Join(keys, ",")
+		}
+		r.code = status.Code(err)
+		res = append(res, r)
+	}
+	return res
+}
+
+// indexSlots returns the keys of the slots of the index entry of the field value, in slot order.
+func indexSlots(s meta.MetaServer, db *memDB, field, value string) []string {
+	entry := s.(*server).ixKey(field, value)
+	kvs := db.entries()
+	n, _ := strconv.Atoi(kvs[entry])
+	var keys []string
+	for i := 0; i < n; i++ {
+		keys = append(keys, kvs[entry+"#"+strconv.Itoa(i)])
+	}
+	return keys
+}
+
+func Test_server_QueryMeta(t *testing.T) {
+	// slots is an index entry whose slots are checked after the operations
+	type slots struct {
+		field string
+		value string
+		keys  []string
+	}
+	type args struct {
+		ops []ixOp
+	}
+	type fields struct {
+		indexFields []string
+	}
+	type want struct {
+		results []ixResult
+		slots   []slots
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, []ixResult, func(field, value string) []string) error
+	}
+	defaultCheckFunc := func(w want, res []ixResult, slotsOf func(field, value string) []string) error {
+		if !reflect.DeepEqual(res, w.results) {
+			return errors.Errorf("got results = %v, want %v", res, w.results)
+		}
+		for _, sl := range w.slots {
+			if keys := slotsOf(sl.field, sl.value); !reflect.DeepEqual(keys, sl.keys) {
+				return errors.Errorf("got slots of %s=%s = %v, want %v", sl.field, sl.value, keys, sl.keys)
+			}
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the slot of a removed key is reused by the last key",
+			args: args{
+				ops: []ixOp{
+					{op: "set", key: "a", val: `{"color":"red"}`},
+					{op: "set", key: "b", val: `{"color":"red"}`},
+					{op: "set", key: "c", val: `{"color":"red"}`},
+					{op: "del", key: "a"},
+					{op: "set", key: "d", val: `{"color":"red"}`},
+					{op: "query", key: "color", val: "red"},
+				},
+			},
+			fields: fields{
+				indexFields: []string{"color"},
+			},
+			want: want{
+				results: []ixResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "b,c,d"},
+				},
+				slots: []slots{
+					{field: "color", value: "red", keys: []string{"c", "b", "d"}},
+				},
+			},
+		},
+		{
+			name: "a key set to another field value moves to its index entry",
+			args: args{
+				ops: []ixOp{
+					{op: "set", key: "a", val: `{"color":"red"}`},
+					{op: "set", key: "a", val: `{"color":"blue"}`},
+					{op: "query", key: "color", val: "red"},
+					{op: "query", key: "color", val: "blue"},
+				},
+			},
+			fields: fields{
+				indexFields: []string{"color"},
+			},
+			want: want{
+				results: []ixResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "a"},
+				},
+				slots: []slots{
+					{field: "color", value: "red"},
+					{field: "color", value: "blue", keys: []string{"a"}},
+				},
+			},
+		},
+		{
+			name: "the nested fields and their array elements are indexed",
+			args: args{
+				ops: []ixOp{
+					{op: "set", key: "a", val: `{"spec":{"color":"red"}}`},
+					{op: "set", key: "b", val: `{"spec":{"color":["red","blue"]}}`},
+					{op: "set", key: "c", val: `{"color":"red"}`},
+					{op: "query", key: "spec.color", val: "red"},
+					{op: "query", key: "spec.color", val: "blue"},
+					{op: "query", key: "color", val: "red"},
+				},
+			},
+			fields: fields{
+				indexFields: []string{"spec.color"},
+			},
+			want: want{
+				results: []ixResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "a,b"},
+					{code: codes.OK, val: "b"},
+					{code: codes.InvalidArgument},
+				},
+			},
+		},
+		{
+			name: "the expired keys are skipped and removed from the index entry",
+			args: args{
+				ops: []ixOp{
+					{op: "set", key: "a", val: `{"color":"red"}`},
+					{op: "set", key: "b", val: `{"color":"red"}`},
+					{op: "expire", key: "a"},
+					{op: "query", key: "color", val: "red"},
+					{op: "expire", key: "b"},
+					{op: "query", key: "color", val: "red"},
+				},
+			},
+			fields: fields{
+				indexFields: []string{"color"},
+			},
+			want: want{
+				results: []ixResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "b"},
+					{code: codes.OK},
+					{code: codes.NotFound},
+				},
+				slots: []slots{
+					{field: "color", value: "red"},
+				},
+			},
+		},
+		{
+			name: "a key set again after it expired is found",
+			args: args{
+				ops: []ixOp{
+					{op: "set", key: "a", val: `{"color":"red"}`},
+					{op: "expire", key: "a"},
+					{op: "set", key: "a", val: `{"color":"red"}`},
+					{op: "query", key: "color", val: "red"},
+				},
+			},
+			fields: fields{
+				indexFields: []string{"color"},
+			},
+			want: want{
+				results: []ixResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "a"},
+				},
+				slots: []slots{
+					{field: "color", value: "red", keys: []string{"a"}},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := newMemDB(nil)
+			s := New(WithHaloDB(db), WithJSONValues(true), WithIndexFields(test.fields.indexFields...))
+
+			res := runIndexOps(s, db, test.args.ops)
+			slotsOf := func(field, value string) []string {
+				return indexSlots(s, db, field, value)
+			}
+			if err := checkFunc(test.want, res, slotsOf); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
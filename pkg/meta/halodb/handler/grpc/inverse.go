@@ -0,0 +1,210 @@
+package grpc
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+)
+
+// keySetHeader prefixes the inverse entries holding several keys under the multi policy.
+// The header is followed by the JSON encoded keys, the latest key comes last.
+const keySetHeader = "\x1bkeys:"
+
+func encodeKeySet(keys []string) (string, error) {
+	bs, err := json.Marshal(keys)
+	if err != nil {
+		return "", err
+	}
+	return keySetHeader + string(bs), nil
+}
+
+// decodeKeySet also accepts the single key entries written under the other policies.
+func decodeKeySet(raw string) ([]string, error) {
+	if !strings.HasPrefix(raw, keySetHeader) {
+		return []string{raw}, nil
+	}
+	var keys []string
+	err := json.Unmarshal([]byte(raw[len(keySetHeader):]), &keys)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// inverseKeys returns the keys holding val, oldest first.
+// Under the multi policy the keys whose value was changed or expired are skipped.
+func (s *server) inverseKeys(val string) ([]string, error) {
+	raw, err := s.haloDB.Get(s.vkKey(val))
+	if err != nil {
+		return nil, err
+	}
+	if s.policy != MultiPolicy {
+		return []string{raw}, nil
+	}
+	keys, err := decodeKeySet(raw)
+	if err != nil {
+		return nil, err
+	}
+	live := keys[:0]
+	for _, key := range keys {
+		if s.owns(key, val) {
+			live = append(live, key)
+		}
+	}
+	if len(live) == 0 {
+		return nil, errors.Errorf("failed to get %s", s.vkKey(val))
+	}
+	return live, nil
+}
+
+// inverseKey returns the latest key holding val.
+func (s *server) inverseKey(val string) (string, error) {
+	if s.policy != MultiPolicy {
+		return s.haloDB.Get(s.vkKey(val))
+	}
+	keys, err := s.inverseKeys(val)
+	if err != nil {
+		return "", err
+	}
+	return keys[len(keys)-1], nil
+}
+
+// putMulti stores the entry and adds key to the inverse entry of val.
+// It must be called with the entry locked.
+func (s *server) putMulti(key, val string, ttl time.Duration, cur string, found bool) (err error) {
+	if ttl > 0 {
+		// the inverse entry is shared with the other keys, so only the entry of key expires
+		err = s.ttl.PutWithTTL(ttl, map[string]string{
+			s.kvKey(key): val,
+		})
+	} else {
+		err = s.haloDB.Put(s.kvKey(key), val)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.updateKeySet(s.vkKey(val), func(keys []string) []string {
+		res := make([]string, 0, len(keys)+1)
+		for _, k := range keys {
+			if k != key && s.owns(k, val) {
+				res = append(res, k)
+			}
+		}
+		return append(res, key)
+	})
+	if err != nil {
+		return err
+	}
+
+	if found && cur != val {
+		s.removeKey(cur, key)
+	}
+	return nil
+}
+
+// removeKey drops key from the inverse entry of val.
+// It must be called with the entry locked.
+func (s *server) removeKey(val, key string) {
+	err := s.updateKeySet(s.vkKey(val), func(keys []string) []string {
+		res := make([]string, 0, len(keys))
+		for _, k := range keys {
+			if k != key {
+				res = append(res, k)
+			}
+		}
+		return res
+	})
+	if err != nil {
+		log.Warnf("[Inverse]\tfailed to remove %s from the inverse entry of %s\t%+v", key, val, err)
+	}
+}
+
+// updateKeySet rewrites the key set stored at entry, it is deleted when fn returns no keys.
+// It must be called with the entry locked, or with the lock guarding it.
+func (s *server) updateKeySet(entry string, fn func(keys []string) []string) error {
+	var keys []string
+	raw, err := s.haloDB.Get(entry)
+	if err == nil {
+		keys, err = decodeKeySet(raw)
+		if err != nil {
+			return err
+		}
+	}
+	keys = fn(keys)
+	if len(keys) == 0 {
+		if len(raw) == 0 {
+			return nil
+		}
+		return s.haloDB.Delete(entry)
+	}
+	v, err := encodeKeySet(keys)
+	if err != nil {
+		return err
+	}
+	return s.haloDB.Put(entry, v)
+}
+
+// deleteKey deletes the entry of key, val is its current value.
+func (s *server) deleteKey(key, val string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.policy != MultiPolicy && len(s.indexFields) == 0 && s.ns == nil && !s.trash {
+		defer s.locks.lock(s.kvKey(key))()
+		return s.haloDB.Delete(s.kvKey(key))
+	}
+
+	defer s.lockEntry(key, "")()
+	// the inverse entry to update is the one of the value locked by lockEntry
+	if cur, err := s.haloDB.Get(s.kvKey(key)); err == nil {
+		val = cur
+	}
+	return s.deleteLocked(key, val)
+}
+
+// deleteLocked deletes the entry of key holding val, it must be called with the entry locked by lockEntry or s.mu held.
+func (s *server) deleteLocked(key, val string) error {
+	if s.trash {
+		err := s.moveToTrash(key, val)
+		if err != nil {
+			return err
+		}
+	}
+	err := s.haloDB.Delete(s.kvKey(key))
+	if err != nil {
+		return err
+	}
+	if s.policy == MultiPolicy {
+		s.removeKey(val, key)
+	} else if s.trash {
+		// a trashed entry must not be found by its value, RestoreMeta sets the inverse entry again
+		owner, err := s.haloDB.Get(s.vkKey(val))
+		if err == nil && owner == key {
+			err = s.haloDB.Delete(s.vkKey(val))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	s.updateIndexes(key, val, "")
+	s.addCount(-1)
+	return nil
+}
+
+// deleteVal deletes the inverse entry of val and returns the keys it pointed at.
+// Only the inverse entry is deleted, the entries of the keys are kept.
+func (s *server) deleteVal(val string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	defer s.locks.lock(s.vkKey(val))()
+
+	keys, err := s.inverseKeys(val)
+	if err != nil {
+		return nil, err
+	}
+	return keys, s.haloDB.Delete(s.vkKey(val))
+}
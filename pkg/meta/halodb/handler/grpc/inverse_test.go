@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/meta"
+	"github.com/vdaas/vald/apis/grpc/payload"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// invOp is an operation of an inverse test, op is one of set, get, del, inv, invall, delinv and delinvall.
+type invOp struct {
+	op  string
+	key string
+	val string
+}
+
+// invResult is the code of an operation, with the value or the keys, joined by ',', it returned.
+type invResult struct {
+	code codes.Code
+	val  string
+}
+
+func runInverseOps(s meta.MetaServer, ops []invOp) []invResult {
+	ext := s.(extension.MetaExtensionServer)
+	ctx := context.Background()
+	res := make([]invResult, 0, len(ops))
+	for _, op := range ops {
+		var (
+			r   invResult
+			err error
+		)
+		switch op.op {
+		case "set":
+			_, err = s.SetMeta(ctx, &payload.Meta_KeyVal{Key: op.key, Val: op.val})
+		case "get":
+			var v *payload.Meta_Val
+			v, err = s.GetMeta(ctx, &payload.Meta_Key{Key: op.key})
+			r.val = v.GetVal()
+		case "del":
+			_, err = s.DeleteMeta(ctx, &payload.Meta_Key{Key: op.key})
+		case "inv":
+			var k *payload.Meta_Key
+			k, err = s.GetMetaInverse(ctx, &payload.Meta_Val{Val: op.val})
+			r.val = k.GetKey()
+		case "delinv":
+			var k *payload.Meta_Key
+			k, err = s.DeleteMetaInverse(ctx, &payload.Meta_Val{Val: op.val})
+			r.val = k.GetKey()
+		case "invall":
+			var ks *payload.Meta_Keys
+			ks, err = ext.GetMetaInverseAll(ctx, &payload.Meta_Val{Val: op.val})
+			r.val = strings.Join(ks.GetKeys(), ",")
+		case "delinvall":
+			var ks *payload.Meta_Keys
+			ks, err = ext.DeleteMetaInverseAll(ctx, &payload.Meta_Val{Val: op.val})
+			r.val = strings.Join(ks.GetKeys(), ",")
+		}
+		r.code = status.Code(err)
+		res = append(res, r)
+	}
+	return res
+}
+
+func Test_server_inverse(t *testing.T) {
+	type args struct {
+		ops []invOp
+	}
+	type fields struct {
+		policy UniquenessPolicy
+	}
+	type want struct {
+		results []invResult
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, []invResult) error
+	}
+	defaultCheckFunc := func(w want, res []invResult) error {
+		if !reflect.DeepEqual(res, w.results) {
+			return errors.Errorf("got results = %v, want %v", res, w.results)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the multi policy keeps every key holding the value, oldest first",
+			args: args{
+				ops: []invOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "b", val: "x"},
+					{op: "set", key: "c", val: "x"},
+					{op: "invall", val: "x"},
+					{op: "inv", val: "x"},
+				},
+			},
+			fields: fields{
+				policy: MultiPolicy,
+			},
+			want: want{
+				results: []invResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "a,b,c"},
+					{code: codes.OK, val: "c"},
+				},
+			},
+		},
+		{
+			name: "the multi policy drops the keys moved to another value or deleted",
+			args: args{
+				ops: []invOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "b", val: "x"},
+					{op: "set", key: "c", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "del", key: "c"},
+					{op: "invall", val: "x"},
+					{op: "inv", val: "x"},
+					{op: "invall", val: "y"},
+				},
+			},
+			fields: fields{
+				policy: MultiPolicy,
+			},
+			want: want{
+				results: []invResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "b"},
+					{code: codes.OK, val: "b"},
+					{code: codes.OK, val: "a"},
+				},
+			},
+		},
+		{
+			name: "DeleteMetaInverse under the multi policy returns the latest key and keeps the entries",
+			args: args{
+				ops: []invOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "b", val: "x"},
+					{op: "delinv", val: "x"},
+					{op: "invall", val: "x"},
+					{op: "get", key: "a"},
+					{op: "get", key: "b"},
+				},
+			},
+			fields: fields{
+				policy: MultiPolicy,
+			},
+			want: want{
+				results: []invResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "b"},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "x"},
+					{code: codes.OK, val: "x"},
+				},
+			},
+		},
+		{
+			name: "DeleteMetaInverseAll under the multi policy returns all the keys and keeps the entries",
+			args: args{
+				ops: []invOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "b", val: "x"},
+					{op: "delinvall", val: "x"},
+					{op: "inv", val: "x"},
+					{op: "get", key: "a"},
+					{op: "get", key: "b"},
+					{op: "delinvall", val: "x"},
+				},
+			},
+			fields: fields{
+				policy: MultiPolicy,
+			},
+			want: want{
+				results: []invResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "a,b"},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "x"},
+					{code: codes.OK, val: "x"},
+					{code: codes.Unknown},
+				},
+			},
+		},
+		{
+			name: "the inverse APIs return the single key of the inverse entry under the allow policy",
+			args: args{
+				ops: []invOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "b", val: "x"},
+					{op: "invall", val: "x"},
+					{op: "inv", val: "x"},
+					{op: "delinvall", val: "x"},
+					{op: "inv", val: "x"},
+					{op: "get", key: "b"},
+				},
+			},
+			fields: fields{
+				policy: AllowPolicy,
+			},
+			want: want{
+				results: []invResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "b"},
+					{code: codes.OK, val: "b"},
+					{code: codes.OK, val: "b"},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "x"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			s := New(WithHaloDB(newMemDB(nil)), WithUniquenessPolicy(test.fields.policy))
+
+			res := runInverseOps(s, test.args.ops)
+			if err := checkFunc(test.want, res); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
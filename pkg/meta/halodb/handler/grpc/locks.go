@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+const lockStripes = 256
+
+// keyLocks are the locks of the HaloDB entries, hashed into stripes.
+// A write holds the stripes of all the entries it reads before writing, so the writes of different keys
+// run concurrently and can be batched by the group commit.
+type keyLocks struct {
+	stripes [lockStripes]sync.RWMutex
+}
+
+func stripe(name string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32() % lockStripes)
+}
+
+// lock locks the stripes of the entries in order, so two writes cannot deadlock, and returns the unlock function.
+func (l *keyLocks) lock(names ...string) func() {
+	idxs := make([]int, 0, len(names))
+	for _, name := range names {
+		idxs = append(idxs, stripe(name))
+	}
+	sort.Ints(idxs)
+	locked := idxs[:0]
+	for i, idx := range idxs {
+		if i > 0 && idx == idxs[i-1] {
+			continue
+		}
+		l.stripes[idx].Lock()
+		locked = append(locked, idx)
+	}
+	return func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			l.stripes[locked[i]].Unlock()
+		}
+	}
+}
+
+// rlock read-locks the stripe of the entry and returns the unlock function.
+func (l *keyLocks) rlock(name string) func() {
+	m := &l.stripes[stripe(name)]
+	m.RLock()
+	return m.RUnlock
+}
+
+// lockEntry locks the entry of key with the inverse entries of its current value and val,
+// and under the overwrite policy the entry of the other key holding val.
+// They are read before locking, so it retries until they have not changed meanwhile.
+// It must be called with s.mu read-locked, val is empty for a delete.
+func (s *server) lockEntry(key, val string) func() {
+	for {
+		cur, found, owner := s.lockedEntries(key, val)
+		names := []string{s.kvKey(key)}
+		if len(val) != 0 {
+			names = append(names, s.vkKey(val))
+		}
+		if found && cur != val {
+			names = append(names, s.vkKey(cur))
+		}
+		if len(owner) != 0 {
+			names = append(names, s.kvKey(owner))
+		}
+		unlock := s.locks.lock(names...)
+		c, f, o := s.lockedEntries(key, val)
+		if c == cur && f == found && o == owner {
+			return unlock
+		}
+		unlock()
+	}
+}
+
+func (s *server) lockedEntries(key, val string) (cur string, found bool, owner string) {
+	cur, err := s.haloDB.Get(s.kvKey(key))
+	found = err == nil
+	if len(val) != 0 && s.policy == OverwritePolicy {
+		owner, err = s.haloDB.Get(s.vkKey(val))
+		if err != nil || owner == key {
+			owner = ""
+		}
+	}
+	return cur, found, owner
+}
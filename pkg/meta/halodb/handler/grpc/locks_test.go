@@ -0,0 +1,191 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/vdaas/vald/apis/grpc/payload"
+)
+
+// blockDB is a memDB whose write of the entry block waits until release is closed.
+type blockDB struct {
+	*memDB
+	block   string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (d *blockDB) Put(key, value string) error {
+	if key == d.block {
+		close(d.entered)
+		<-d.release
+	}
+	return d.memDB.Put(key, value)
+}
+
+func Test_server_lockEntry(t *testing.T) {
+	type write struct {
+		key string
+		val string
+	}
+	type args struct {
+		// first is blocked while it writes its entry, second is started meanwhile
+		first  write
+		second write
+	}
+	type fields struct {
+		kvs    map[string]string
+		policy UniquenessPolicy
+	}
+	type want struct {
+		waits bool
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, bool) error
+	}
+	defaultCheckFunc := func(w want, waits bool) error {
+		if waits != w.waits {
+			return errors.Errorf("got waits = %v, want %v", waits, w.waits)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "a write of another key and value does not wait",
+			args: args{
+				first:  write{key: "a", val: "x"},
+				second: write{key: "b", val: "y"},
+			},
+			fields: fields{
+				policy: AllowPolicy,
+			},
+			want: want{
+				waits: false,
+			},
+		},
+		{
+			name: "a write of the same key waits",
+			args: args{
+				first:  write{key: "a", val: "x"},
+				second: write{key: "a", val: "y"},
+			},
+			fields: fields{
+				policy: AllowPolicy,
+			},
+			want: want{
+				waits: true,
+			},
+		},
+		{
+			name: "a write of another key sharing the inverse entry waits",
+			args: args{
+				first:  write{key: "a", val: "x"},
+				second: write{key: "b", val: "x"},
+			},
+			fields: fields{
+				policy: AllowPolicy,
+			},
+			want: want{
+				waits: true,
+			},
+		},
+		{
+			name: "a write of another key and value does not wait under the reject policy",
+			args: args{
+				first:  write{key: "a", val: "x"},
+				second: write{key: "b", val: "y"},
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"kv:a": "z",
+					"vk:z": "a",
+				},
+				policy: RejectPolicy,
+			},
+			want: want{
+				waits: false,
+			},
+		},
+		{
+			name: "a write of the previous value of the key waits under the reject policy",
+			args: args{
+				first:  write{key: "a", val: "x"},
+				second: write{key: "b", val: "z"},
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"kv:a": "z",
+					"vk:z": "a",
+				},
+				policy: RejectPolicy,
+			},
+			want: want{
+				waits: true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			// the entries of the test hash to different stripes, so only the shared entries are waited for
+			seen := make(map[int]string)
+			for _, name := range []string{"kv:a", "kv:b", "vk:x", "vk:y", "vk:z"} {
+				if other, ok := seen[stripe(name)]; ok {
+					tt.Fatalf("%s and %s share a stripe", name, other)
+				}
+				seen[stripe(name)] = name
+			}
+			db := &blockDB{
+				memDB:   newMemDB(test.fields.kvs),
+				block:   "kv:" + test.args.first.key,
+				entered: make(chan struct{}),
+				release: make(chan struct{}),
+			}
+			s := New(WithHaloDB(db), WithUniquenessPolicy(test.fields.policy))
+
+			first := make(chan error, 1)
+			go func() {
+				_, err := s.SetMeta(context.Background(), &payload.Meta_KeyVal{Key: test.args.first.key, Val: test.args.first.val})
+				first <- err
+			}()
+			<-db.entered
+			db.block = ""
+			second := make(chan error, 1)
+			go func() {
+				_, err := s.SetMeta(context.Background(), &payload.Meta_KeyVal{Key: test.args.second.key, Val: test.args.second.val})
+				second <- err
+			}()
+			var waits bool
+			select {
+			case err := <-second:
+				if err != nil {
+					tt.Fatal(err)
+				}
+			case <-time.After(100 * time.Millisecond):
+				waits = true
+			}
+			close(db.release)
+			if err := <-first; err != nil {
+				tt.Fatal(err)
+			}
+			if waits {
+				if err := <-second; err != nil {
+					tt.Fatal(err)
+				}
+			}
+			if err := checkFunc(test.want, waits); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/timeutil"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// metadataKeyPrefix is the prefix of all the gRPC metadata keys handled by the meta APIs.
+	metadataKeyPrefix = "meta-"
+
+	// TTLMetadataKey is the gRPC metadata key to set the TTL of the entries in SetMeta(s).
+	TTLMetadataKey = "meta-ttl"
+
+	// NamespaceMetadataKey is the gRPC metadata key to select the namespace of the meta APIs.
+	NamespaceMetadataKey = "meta-namespace"
+
+	// VersionMetadataKey is the gRPC response header key carrying the version of the entry.
+	VersionMetadataKey = "meta-version"
+
+	// ExpectedVersionMetadataKey is the gRPC metadata key to make SetMeta and DeleteMeta fail unless the entry has the version.
+	ExpectedVersionMetadataKey = "meta-expected-version"
+)
+
+func metadataValue(ctx context.Context, key string) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	vals := md.Get(key)
+	if len(vals) == 0 {
+		return "", false
+	}
+	return vals[0], true
+}
+
+func ttlFromContext(ctx context.Context) (time.Duration, error) {
+	v, ok := metadataValue(ctx, TTLMetadataKey)
+	if !ok {
+		return 0, nil
+	}
+	return timeutil.Parse(v)
+}
+
+// forwardMetadata copies the incoming meta metadata to the outgoing context.
+func forwardMetadata(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	out := metadata.MD{}
+	for key, vals := range md {
+		if strings.HasPrefix(key, metadataKeyPrefix) {
+			out[key] = vals
+		}
+	}
+	if len(out) == 0 {
+		return ctx
+	}
+	return metadata.NewOutgoingContext(ctx, out)
+}
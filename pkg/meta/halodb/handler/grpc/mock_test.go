@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+)
+
+func TestMain(m *testing.M) {
+	log.Init()
+	os.Exit(m.Run())
+}
+
+// memDB is an in-memory HaloDB which commits the transactions like the journal.
+type memDB struct {
+	mu      sync.Mutex
+	m       map[string]string
+	commits int
+}
+
+func newMemDB(kvs map[string]string) *memDB {
+	m := make(map[string]string, len(kvs))
+	for k, v := range kvs {
+		m[k] = v
+	}
+	return &memDB{
+		m: m,
+	}
+}
+
+func (d *memDB) Open(path string) error {
+	return nil
+}
+
+func (d *memDB) Put(key, value string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.m[key] = value
+	return nil
+}
+
+func (d *memDB) Get(key string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	v, ok := d.m[key]
+	if !ok {
+		return "", errors.Errorf("%s not found", key)
+	}
+	return v, nil
+}
+
+func (d *memDB) Delete(key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.m[key]; !ok {
+		return errors.Errorf("%s not found", key)
+	}
+	delete(d.m, key)
+	return nil
+}
+
+func (d *memDB) Size() (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return int64(len(d.m)), nil
+}
+
+func (d *memDB) Close() error {
+	return nil
+}
+
+func (d *memDB) Commit(ms []service.Mutation) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.commits++
+	for _, m := range ms {
+		if m.Delete {
+			delete(d.m, m.Key)
+		} else {
+			d.m[m.Key] = m.Value
+		}
+	}
+	return nil
+}
+
+// entries returns a copy of the entries.
+func (d *memDB) entries() map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	m := make(map[string]string, len(d.m))
+	for k, v := range d.m {
+		m[k] = v
+	}
+	return m
+}
+
+// memTenant is a tenant whose HaloDB is a memDB, it is emptied by the truncation.
+type memTenant struct {
+	*memDB
+	name      string
+	truncates int
+}
+
+func newMemTenant(name string) *memTenant {
+	return &memTenant{
+		memDB: newMemDB(nil),
+		name:  name,
+	}
+}
+
+func (t *memTenant) Write(ms []service.Mutation) []error {
+	errs := make([]error, len(ms))
+	for i, m := range ms {
+		if m.Delete {
+			errs[i] = t.Delete(m.Key)
+		} else {
+			errs[i] = t.Put(m.Key, m.Value)
+		}
+	}
+	return errs
+}
+
+func (t *memTenant) Name() string {
+	return t.name
+}
+
+func (t *memTenant) Opened() bool {
+	return true
+}
+
+func (t *memTenant) Maintain(now time.Time) error {
+	return nil
+}
+
+func (t *memTenant) Truncate() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.truncates++
+	t.m = make(map[string]string)
+	return nil
+}
@@ -0,0 +1,431 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/info"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc/status"
+	"github.com/rinx/vald-meta-halodb/internal/observability/trace"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+	"github.com/vdaas/vald/apis/grpc/payload"
+)
+
+// countKey holds the number of entries of a namespace.
+const countKey = "st:count"
+
+var errNoDataDirectory = errors.New("namespace has no data directory")
+
+type namespaceConfig struct {
+	name  string
+	quota int64
+	// haloDB is the dedicated HaloDB of the tenant, the namespace shares the default one when it is nil
+	haloDB service.HaloDB
+	tenant service.Tenant
+}
+
+// namespaces passes the meta APIs to the server of the namespace in the request metadata.
+// The requests without namespace use the default keyspace.
+type namespaces struct {
+	*server
+	servers map[string]*server
+}
+
+func newNamespaces(s *server) *namespaces {
+	n := &namespaces{
+		server:  s,
+		servers: make(map[string]*server, len(s.namespaces)),
+	}
+	for _, cfg := range s.namespaces {
+		h := s.haloDB
+		if cfg.haloDB != nil {
+			h = cfg.haloDB
+		}
+		ns, err := service.NewNamespace(h, cfg.name)
+		if err != nil {
+			log.Errorf("[Namespace]\tinvalid namespace %s\t%+v", cfg.name, err)
+			continue
+		}
+		child := &server{
+			haloDB:       ns,
+			policy:       s.policy,
+			jsonValues:   s.jsonValues,
+			indexFields:  s.indexFields,
+			ns:           ns,
+			quota:        cfg.quota,
+			historySize:  s.historySize,
+			versioning:   s.versioning,
+			trash:        s.trash,
+			trashPending: s.trashPending,
+			tenant:       cfg.tenant,
+		}
+		if s.ttl != nil && cfg.haloDB == nil {
+			child.ttl = ns
+		}
+		n.servers[cfg.name] = child
+	}
+	return n
+}
+
+func (n *namespaces) get(ctx context.Context) (*server, error) {
+	name, ok := metadataValue(ctx, NamespaceMetadataKey)
+	if !ok || len(name) == 0 {
+		return n.server, nil
+	}
+	return n.lookup(name)
+}
+
+func (n *namespaces) lookup(name string) (*server, error) {
+	s, ok := n.servers[name]
+	if !ok {
+		return nil, status.WrapWithPermissionDenied(fmt.Sprintf("namespace %s is not allowed", name), nil, info.Get())
+	}
+	return s, nil
+}
+
+// count returns the number of entries of the namespace.
+func (s *server) count() int64 {
+	raw, err := s.haloDB.Get(countKey)
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// addCount is a no-op out of the namespaces.
+func (s *server) addCount(delta int64) {
+	if s.ns == nil {
+		return
+	}
+	s.countMu.Lock()
+	defer s.countMu.Unlock()
+
+	n := s.count() + delta
+	if n < 0 {
+		n = 0
+	}
+	err := s.haloDB.Put(countKey, strconv.FormatInt(n, 10))
+	if err != nil {
+		log.Warnf("[Namespace]\tfailed to update the entry count of %s\t%+v", s.ns.Name(), err)
+	}
+}
+
+// reserveCount counts a new entry, it fails when the namespace has reached its quota.
+func (s *server) reserveCount() error {
+	s.countMu.Lock()
+	defer s.countMu.Unlock()
+
+	n := s.count()
+	if s.quota > 0 && n >= s.quota {
+		return errQuotaExceeded
+	}
+	return s.haloDB.Put(countKey, strconv.FormatInt(n+1, 10))
+}
+
+func (s *server) NamespaceStats(ctx context.Context, req *extension.NamespaceRequest) (*extension.NamespaceStats, error) {
+	if s.ns == nil || s.ns.Name() != req.GetNamespace() {
+		return nil, status.WrapWithPermissionDenied(fmt.Sprintf("NamespaceStats API namespace %s is not allowed", req.GetNamespace()), nil, info.Get())
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &extension.NamespaceStats{
+		Namespace: s.ns.Name(),
+		Count:     s.count(),
+		Quota:     s.quota,
+	}, nil
+}
+
+func (s *server) DropNamespace(ctx context.Context, req *extension.NamespaceRequest) (*payload.Empty, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.DropNamespace")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	if s.ns == nil || s.ns.Name() != req.GetNamespace() {
+		if span != nil {
+			span.SetStatus(trace.StatusCodePermissionDenied(fmt.Sprintf("namespace %s is not allowed", req.GetNamespace())))
+		}
+		return nil, status.WrapWithPermissionDenied(fmt.Sprintf("DropNamespace API namespace %s is not allowed", req.GetNamespace()), nil, info.Get())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ns.Drop()
+	if err != nil {
+		log.Errorf("[DropNamespace]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInternal(err.Error()))
+		}
+		return nil, status.WrapWithInternal(fmt.Sprintf("DropNamespace API namespace %s failed to drop", req.GetNamespace()), err, info.Get())
+	}
+	log.Infof("[DropNamespace]\tnamespace %s dropped", req.GetNamespace())
+	return new(payload.Empty), nil
+}
+
+func (s *server) TruncateNamespace(ctx context.Context, req *extension.NamespaceRequest) (*payload.Empty, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.TruncateNamespace")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	if s.ns == nil || s.ns.Name() != req.GetNamespace() {
+		if span != nil {
+			span.SetStatus(trace.StatusCodePermissionDenied(fmt.Sprintf("namespace %s is not allowed", req.GetNamespace())))
+		}
+		return nil, status.WrapWithPermissionDenied(fmt.Sprintf("TruncateNamespace API namespace %s is not allowed", req.GetNamespace()), nil, info.Get())
+	}
+	if s.tenant == nil {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeFailedPrecondition(errNoDataDirectory.Error()))
+		}
+		return nil, status.WrapWithFailedPrecondition(fmt.Sprintf("TruncateNamespace API namespace %s has no data directory", req.GetNamespace()), errNoDataDirectory, info.Get())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.tenant.Truncate()
+	if err != nil {
+		log.Errorf("[TruncateNamespace]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInternal(err.Error()))
+		}
+		return nil, status.WrapWithInternal(fmt.Sprintf("TruncateNamespace API namespace %s failed to truncate", req.GetNamespace()), err, info.Get())
+	}
+	s.ns.Reset()
+	s.trashPending = false
+	log.Infof("[TruncateNamespace]\tnamespace %s truncated", req.GetNamespace())
+	return new(payload.Empty), nil
+}
+
+func (n *namespaces) NamespaceStats(ctx context.Context, req *extension.NamespaceRequest) (*extension.NamespaceStats, error) {
+	s, err := n.lookup(req.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	return s.NamespaceStats(ctx, req)
+}
+
+func (n *namespaces) DropNamespace(ctx context.Context, req *extension.NamespaceRequest) (*payload.Empty, error) {
+	s, err := n.lookup(req.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	return s.DropNamespace(ctx, req)
+}
+
+func (n *namespaces) TruncateNamespace(ctx context.Context, req *extension.NamespaceRequest) (*payload.Empty, error) {
+	s, err := n.lookup(req.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	return s.TruncateNamespace(ctx, req)
+}
+
+func (n *namespaces) GetMeta(ctx context.Context, key *payload.Meta_Key) (*payload.Meta_Val, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMeta(ctx, key)
+}
+
+func (n *namespaces) GetMetas(ctx context.Context, keys *payload.Meta_Keys) (*payload.Meta_Vals, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMetas(ctx, keys)
+}
+
+func (n *namespaces) GetMetaInverse(ctx context.Context, val *payload.Meta_Val) (*payload.Meta_Key, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMetaInverse(ctx, val)
+}
+
+func (n *namespaces) GetMetasInverse(ctx context.Context, vals *payload.Meta_Vals) (*payload.Meta_Keys, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMetasInverse(ctx, vals)
+}
+
+func (n *namespaces) SetMeta(ctx context.Context, kv *payload.Meta_KeyVal) (*payload.Empty, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.SetMeta(ctx, kv)
+}
+
+func (n *namespaces) SetMetas(ctx context.Context, kvs *payload.Meta_KeyVals) (*payload.Empty, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.SetMetas(ctx, kvs)
+}
+
+func (n *namespaces) DeleteMeta(ctx context.Context, key *payload.Meta_Key) (*payload.Meta_Val, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.DeleteMeta(ctx, key)
+}
+
+func (n *namespaces) DeleteMetas(ctx context.Context, keys *payload.Meta_Keys) (*payload.Meta_Vals, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.DeleteMetas(ctx, keys)
+}
+
+func (n *namespaces) DeleteMetaInverse(ctx context.Context, val *payload.Meta_Val) (*payload.Meta_Key, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.DeleteMetaInverse(ctx, val)
+}
+
+func (n *namespaces) DeleteMetasInverse(ctx context.Context, vals *payload.Meta_Vals) (*payload.Meta_Keys, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.DeleteMetasInverse(ctx, vals)
+}
+
+func (n *namespaces) SetMetaIfAbsent(ctx context.Context, kv *payload.Meta_KeyVal) (*payload.Empty, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.SetMetaIfAbsent(ctx, kv)
+}
+
+func (n *namespaces) CompareAndSetMeta(ctx context.Context, req *extension.CompareAndSetRequest) (*payload.Empty, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.CompareAndSetMeta(ctx, req)
+}
+
+func (n *namespaces) GetMetaInverseAll(ctx context.Context, val *payload.Meta_Val) (*payload.Meta_Keys, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMetaInverseAll(ctx, val)
+}
+
+func (n *namespaces) DeleteMetaInverseAll(ctx context.Context, val *payload.Meta_Val) (*payload.Meta_Keys, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.DeleteMetaInverseAll(ctx, val)
+}
+
+func (n *namespaces) QueryMeta(ctx context.Context, req *extension.IndexQueryRequest) (*payload.Meta_Keys, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.QueryMeta(ctx, req)
+}
+
+func (n *namespaces) GetMetaHistory(ctx context.Context, key *payload.Meta_Key) (*extension.MetaHistory, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMetaHistory(ctx, key)
+}
+
+func (n *namespaces) RollbackMeta(ctx context.Context, req *extension.RollbackRequest) (*payload.Empty, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.RollbackMeta(ctx, req)
+}
+
+func (n *namespaces) Transaction(ctx context.Context, req *extension.TransactionRequest) (*extension.TransactionResponse, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.Transaction(ctx, req)
+}
+
+func (n *namespaces) sweepTrash(now time.Time, retention time.Duration) (purged uint64, errs error) {
+	purged, errs = n.server.sweepTrash(now, retention)
+	for name, s := range n.servers {
+		p, err := s.sweepTrash(now, retention)
+		purged += p
+		if err != nil {
+			log.Warnf("[Trash]\tfailed to sweep the trash of namespace %s\t%+v", name, err)
+			errs = errors.Wrap(errs, err.Error())
+		}
+	}
+	return purged, errs
+}
+
+func (n *namespaces) ListTrash(ctx context.Context, req *extension.TrashListRequest) (*extension.TrashEntries, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListTrash(ctx, req)
+}
+
+func (n *namespaces) RestoreMeta(ctx context.Context, key *payload.Meta_Key) (*payload.Meta_Val, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.RestoreMeta(ctx, key)
+}
+
+func (n *namespaces) SwapMetas(ctx context.Context, kvs *payload.Meta_KeyVals) (*payload.Meta_Vals, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.SwapMetas(ctx, kvs)
+}
+
+func (n *namespaces) SetMetasInverse(ctx context.Context, kvs *payload.Meta_KeyVals) (*payload.Empty, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.SetMetasInverse(ctx, kvs)
+}
+
+func (n *namespaces) UnsetMetasInverse(ctx context.Context, kvs *payload.Meta_KeyVals) (*payload.Empty, error) {
+	s, err := n.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.UnsetMetasInverse(ctx, kvs)
+}
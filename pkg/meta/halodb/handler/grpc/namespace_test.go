@@ -0,0 +1,246 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/meta"
+	"github.com/vdaas/vald/apis/grpc/payload"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// nsOp is an operation of a namespace test, op is one of set, get, del, drop, truncate and stats.
+type nsOp struct {
+	ns  string
+	op  string
+	key string
+	val string
+}
+
+// nsResult is the code of an operation, with the value read by get or the count returned by stats.
+type nsResult struct {
+	code codes.Code
+	val  string
+}
+
+func runNamespaceOps(s meta.MetaServer, ops []nsOp) []nsResult {
+	ext := s.(extension.MetaExtensionServer)
+	res := make([]nsResult, 0, len(ops))
+	for _, op := range ops {
+		ctx := context.Background()
+		if len(op.ns) != 0 {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(NamespaceMetadataKey, op.ns))
+		}
+		var (
+			r   nsResult
+			err error
+		)
+		switch op.op {
+		case "set":
+			_, err = s.SetMeta(ctx, &payload.Meta_KeyVal{Key: op.key, Val: op.val})
+		case "get":
+			var v *payload.Meta_Val
+			v, err = s.GetMeta(ctx, &payload.Meta_Key{Key: op.key})
+			r.val = v.GetVal()
+		case "del":
+			_, err = s.DeleteMeta(ctx, &payload.Meta_Key{Key: op.key})
+		case "drop":
+			_, err = ext.DropNamespace(ctx, &extension.NamespaceRequest{Namespace: op.ns})
+		case "truncate":
+			_, err = ext.TruncateNamespace(ctx, &extension.NamespaceRequest{Namespace: op.ns})
+		case "stats":
+			var st *extension.NamespaceStats
+			st, err = ext.NamespaceStats(ctx, &extension.NamespaceRequest{Namespace: op.ns})
+			if err == nil {
+				r.val = strconv.FormatInt(st.GetCount(), 10)
+			}
+		}
+		r.code = status.Code(err)
+		res = append(res, r)
+	}
+	return res
+}
+
+func Test_namespaces(t *testing.T) {
+	type args struct {
+		ops []nsOp
+	}
+	type want struct {
+		results []nsResult
+	}
+	type test struct {
+		name      string
+		args      args
+		want      want
+		checkFunc func(want, []nsResult) error
+	}
+	defaultCheckFunc := func(w want, res []nsResult) error {
+		if !reflect.DeepEqual(res, w.results) {
+			return errors.Errorf("got results = %v, want %v", res, w.results)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the namespaces and the default keyspace are isolated",
+			args: args{
+				ops: []nsOp{
+					{ns: "a", op: "set", key: "k", val: "1"},
+					{ns: "b", op: "set", key: "k", val: "2"},
+					{ns: "a", op: "get", key: "k"},
+					{ns: "b", op: "get", key: "k"},
+					{op: "get", key: "k"},
+				},
+			},
+			want: want{
+				results: []nsResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "1"},
+					{code: codes.OK, val: "2"},
+					{code: codes.NotFound},
+				},
+			},
+		},
+		{
+			name: "a namespace at its quota rejects the new keys only",
+			args: args{
+				ops: []nsOp{
+					{ns: "a", op: "set", key: "k1", val: "1"},
+					{ns: "a", op: "set", key: "k2", val: "2"},
+					{ns: "a", op: "set", key: "k3", val: "3"},
+					{ns: "a", op: "set", key: "k1", val: "4"},
+					{ns: "a", op: "del", key: "k2"},
+					{ns: "a", op: "set", key: "k3", val: "3"},
+					{ns: "a", op: "stats"},
+				},
+			},
+			want: want{
+				results: []nsResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.ResourceExhausted},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "2"},
+				},
+			},
+		},
+		{
+			name: "a dropped namespace hides its entries and starts empty",
+			args: args{
+				ops: []nsOp{
+					{ns: "b", op: "set", key: "k", val: "1"},
+					{ns: "b", op: "drop"},
+					{ns: "b", op: "get", key: "k"},
+					{ns: "b", op: "stats"},
+					{ns: "b", op: "set", key: "k", val: "2"},
+					{ns: "b", op: "get", key: "k"},
+				},
+			},
+			want: want{
+				results: []nsResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "0"},
+					{code: codes.OK},
+					{code: codes.OK, val: "2"},
+				},
+			},
+		},
+		{
+			name: "a truncated tenant namespace starts empty",
+			args: args{
+				ops: []nsOp{
+					{ns: "t", op: "set", key: "k1", val: "1"},
+					{ns: "t", op: "set", key: "k2", val: "2"},
+					{ns: "t", op: "drop"},
+					{ns: "t", op: "truncate"},
+					{ns: "t", op: "get", key: "k1"},
+					{ns: "t", op: "stats"},
+					{ns: "t", op: "set", key: "k2", val: "3"},
+					{ns: "t", op: "get", key: "k2"},
+				},
+			},
+			want: want{
+				results: []nsResult{
+					{code: codes.OK},
+					{code: codes.ResourceExhausted},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "0"},
+					{code: codes.OK},
+					{code: codes.OK, val: "3"},
+				},
+			},
+		},
+		{
+			name: "a namespace in the default HaloDB cannot be truncated",
+			args: args{
+				ops: []nsOp{
+					{ns: "a", op: "set", key: "k", val: "1"},
+					{ns: "a", op: "truncate"},
+					{ns: "a", op: "get", key: "k"},
+				},
+			},
+			want: want{
+				results: []nsResult{
+					{code: codes.OK},
+					{code: codes.FailedPrecondition},
+					{code: codes.OK, val: "1"},
+				},
+			},
+		},
+		{
+			name: "an unknown namespace is denied",
+			args: args{
+				ops: []nsOp{
+					{ns: "c", op: "set", key: "k", val: "1"},
+					{ns: "c", op: "get", key: "k"},
+					{ns: "c", op: "drop"},
+					{ns: "c", op: "truncate"},
+					{ns: "c", op: "stats"},
+				},
+			},
+			want: want{
+				results: []nsResult{
+					{code: codes.PermissionDenied},
+					{code: codes.PermissionDenied},
+					{code: codes.PermissionDenied},
+					{code: codes.PermissionDenied},
+					{code: codes.PermissionDenied},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			tn := newMemTenant("t")
+			s := New(
+				WithHaloDB(newMemDB(nil)),
+				WithNamespace("a", 2),
+				WithNamespace("b", 0),
+				WithTenantNamespace("t", 1, tn, tn),
+			)
+
+			res := runNamespaceOps(s, test.args.ops)
+			if err := checkFunc(test.want, res); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
@@ -5,7 +5,9 @@ import "github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
 type Option func(*server)
 
 var (
-	defaultOpts = []Option{}
+	defaultOpts = []Option{
+		WithUniquenessPolicy(AllowPolicy),
+	}
 )
 
 func WithHaloDB(h service.HaloDB) Option {
@@ -13,3 +15,86 @@ func WithHaloDB(h service.HaloDB) Option {
 		s.haloDB = h
 	}
 }
+
+// WithTTL sets the TTL enabled HaloDB, it is used for all the APIs.
+func WithTTL(t service.TTL) Option {
+	return func(s *server) {
+		s.haloDB = t
+		s.ttl = t
+	}
+}
+
+func WithUniquenessPolicy(p UniquenessPolicy) Option {
+	return func(s *server) {
+		if len(p) != 0 {
+			s.policy = p
+		}
+	}
+}
+
+// WithJSONValues makes SetMeta reject the values which are not valid JSON.
+func WithJSONValues(enabled bool) Option {
+	return func(s *server) {
+		s.jsonValues = enabled
+	}
+}
+
+// WithIndexFields sets the JSON fields to index, nested fields are separated by '.'.
+func WithIndexFields(fields ...string) Option {
+	return func(s *server) {
+		for _, f := range fields {
+			if len(f) != 0 {
+				s.indexFields = append(s.indexFields, f)
+			}
+		}
+	}
+}
+
+// WithHistorySize keeps the last n versions of each key, the history is disabled when n is not positive.
+func WithHistorySize(n int) Option {
+	return func(s *server) {
+		s.historySize = n
+	}
+}
+
+// WithVersioning gives every entry a version, which SetMeta and DeleteMeta can be conditioned on.
+func WithVersioning(enabled bool) Option {
+	return func(s *server) {
+		s.versioning = enabled
+	}
+}
+
+// WithTrash makes the deletes move the entries to the trash, from which they can be listed and restored.
+func WithTrash(enabled bool) Option {
+	return func(s *server) {
+		s.trash = enabled
+		s.trashPending = enabled
+	}
+}
+
+// WithNamespace allows the namespace, quota limits its number of entries when it is positive.
+func WithNamespace(name string, quota int64) Option {
+	return func(s *server) {
+		if len(name) != 0 {
+			s.namespaces = append(s.namespaces, namespaceConfig{
+				name:  name,
+				quota: quota,
+			})
+		}
+	}
+}
+
+// WithTenantNamespace allows the namespace whose entries are stored in the HaloDB of the tenant t,
+// h is the HaloDB of t with its encodings.
+func WithTenantNamespace(name string, quota int64, t service.Tenant, h service.HaloDB) Option {
+	return func(s *server) {
+		if len(name) != 0 && t != nil && h != nil {
+			s.namespaces = append(s.namespaces, namespaceConfig{
+				name:   name,
+				quota:  quota,
+				haloDB: h,
+				tenant: t,
+			})
+		}
+	}
+}
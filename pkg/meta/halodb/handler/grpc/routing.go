@@ -0,0 +1,155 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/info"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc/status"
+	"github.com/rinx/vald-meta-halodb/internal/observability/trace"
+	"github.com/vdaas/vald/apis/grpc/payload"
+)
+
+var errInverseOnlyMulti = errors.New("inverse entries cannot be stored alone under the multi policy")
+
+// setInverse stores only the inverse entry of val, for the router to place it on the owner of the value.
+func (s *server) setInverse(key, val string, ttl time.Duration) error {
+	if s.policy == MultiPolicy {
+		// the keys of a multi inverse entry are checked against the entries of this server
+		return errInverseOnlyMulti
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	defer s.locks.lock(s.vkKey(val))()
+
+	if ttl > 0 {
+		return s.ttl.PutWithTTL(ttl, map[string]string{
+			s.vkKey(val): key,
+		})
+	}
+	return s.haloDB.Put(s.vkKey(val), key)
+}
+
+// unsetInverse deletes the inverse entry of val when it still points at key.
+func (s *server) unsetInverse(key, val string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	defer s.locks.lock(s.vkKey(val))()
+
+	owner, err := s.haloDB.Get(s.vkKey(val))
+	if err != nil || owner != key {
+		return nil
+	}
+	return s.haloDB.Delete(s.vkKey(val))
+}
+
+func (s *server) SwapMetas(ctx context.Context, kvs *payload.Meta_KeyVals) (_ *payload.Meta_Vals, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.SwapMetas")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	ttl, err := ttlFromContext(ctx)
+	if err != nil || ttl < 0 || (ttl > 0 && s.ttl == nil) {
+		log.Warnf("[SwapMetas]\tinvalid ttl\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(fmt.Sprintf("invalid ttl %s", ttl)))
+		}
+		return nil, status.WrapWithInvalidArgument("SwapMetas API haloDB invalid ttl", err, info.Get())
+	}
+	mv := &payload.Meta_Vals{
+		Vals: make([]string, 0, len(kvs.GetKvs())),
+	}
+	for _, kv := range kvs.GetKvs() {
+		var prev string
+		_, err = s.setMeta(kv.GetKey(), kv.GetVal(), ttl, func(cur string, found bool) error {
+			prev = cur
+			return nil
+		})
+		switch err {
+		case nil:
+			mv.Vals = append(mv.Vals, prev)
+			continue
+		case errValueAlreadyExists:
+			if span != nil {
+				span.SetStatus(trace.StatusCodeAlreadyExists(err.Error()))
+			}
+			return nil, status.WrapWithAlreadyExists(fmt.Sprintf("SwapMetas API haloDB key %s val %s already exists", kv.GetKey(), kv.GetVal()), err, info.Get())
+		case errInvalidJSON:
+			if span != nil {
+				span.SetStatus(trace.StatusCodeInvalidArgument(err.Error()))
+			}
+			return nil, status.WrapWithInvalidArgument(fmt.Sprintf("SwapMetas API haloDB key %s val %s is not a valid JSON", kv.GetKey(), kv.GetVal()), err, info.Get())
+		case errQuotaExceeded:
+			if span != nil {
+				span.SetStatus(trace.StatusCodeResourceExhausted(err.Error()))
+			}
+			return nil, status.WrapWithResourceExhausted(fmt.Sprintf("SwapMetas API haloDB key %s val %s namespace quota exceeded", kv.GetKey(), kv.GetVal()), err, info.Get())
+		}
+		log.Errorf("[SwapMetas]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInternal(err.Error()))
+		}
+		return nil, status.WrapWithInternal(fmt.Sprintf("SwapMetas API haloDB key %s val %s failed to store", kv.GetKey(), kv.GetVal()), err, info.Get())
+	}
+	return mv, nil
+}
+
+func (s *server) SetMetasInverse(ctx context.Context, kvs *payload.Meta_KeyVals) (_ *payload.Empty, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.SetMetasInverse")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	ttl, err := ttlFromContext(ctx)
+	if err != nil || ttl < 0 || (ttl > 0 && s.ttl == nil) {
+		log.Warnf("[SetMetasInverse]\tinvalid ttl\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInvalidArgument(fmt.Sprintf("invalid ttl %s", ttl)))
+		}
+		return nil, status.WrapWithInvalidArgument("SetMetasInverse API haloDB invalid ttl", err, info.Get())
+	}
+	for _, kv := range kvs.GetKvs() {
+		err = s.setInverse(kv.GetKey(), kv.GetVal(), ttl)
+		if err == errInverseOnlyMulti {
+			if span != nil {
+				span.SetStatus(trace.StatusCodeFailedPrecondition(err.Error()))
+			}
+			return nil, status.WrapWithFailedPrecondition("SetMetasInverse API haloDB inverse entries cannot be stored alone under the multi policy", err, info.Get())
+		}
+		if err != nil {
+			log.Errorf("[SetMetasInverse]\tunknown error\t%+v", err)
+			if span != nil {
+				span.SetStatus(trace.StatusCodeInternal(err.Error()))
+			}
+			return nil, status.WrapWithInternal(fmt.Sprintf("SetMetasInverse API haloDB key %s val %s failed to store", kv.GetKey(), kv.GetVal()), err, info.Get())
+		}
+	}
+	return new(payload.Empty), nil
+}
+
+func (s *server) UnsetMetasInverse(ctx context.Context, kvs *payload.Meta_KeyVals) (_ *payload.Empty, err error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.UnsetMetasInverse")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	for _, kv := range kvs.GetKvs() {
+		err = s.unsetInverse(kv.GetKey(), kv.GetVal())
+		if err != nil {
+			log.Errorf("[UnsetMetasInverse]\tunknown error\t%+v", err)
+			if span != nil {
+				span.SetStatus(trace.StatusCodeInternal(err.Error()))
+			}
+			return nil, status.WrapWithInternal(fmt.Sprintf("UnsetMetasInverse API haloDB key %s val %s failed to delete", kv.GetKey(), kv.GetVal()), err, info.Get())
+		}
+	}
+	return new(payload.Empty), nil
+}
@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"strconv"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+// The slot entries hold a set of keys, one per slot, for the sets which grow with the number of keys,
+// like the index entries and the trash buckets. The entry holds the number of slots,
+// slotKey holds the key of each slot and slotPosKey the slot of each key,
+// so adding or removing a key costs the same whatever the number of keys.
+
+func (s *server) slotKey(entry string, i int64) string {
+	return entry + "#" + strconv.FormatInt(i, 10)
+}
+
+func (s *server) slotPosKey(entry, key string) string {
+	return entry + "@" + key
+}
+
+func (s *server) slotLen(entry string) int64 {
+	raw, err := s.haloDB.Get(entry)
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// addSlotKey stores key in the next slot of the entry.
+func (s *server) addSlotKey(entry, key string) error {
+	defer s.slotLocks.lock(entry)()
+
+	if _, err := s.haloDB.Get(s.slotPosKey(entry, key)); err == nil {
+		return nil
+	}
+	n := s.slotLen(entry)
+	err := s.haloDB.Put(s.slotKey(entry, n), key)
+	if err != nil {
+		return err
+	}
+	err = s.haloDB.Put(s.slotPosKey(entry, key), strconv.FormatInt(n, 10))
+	if err != nil {
+		return err
+	}
+	return s.haloDB.Put(entry, strconv.FormatInt(n+1, 10))
+}
+
+// removeSlotKey moves the key of the last slot of the entry to the slot of key.
+// The entry is deleted with its last key.
+func (s *server) removeSlotKey(entry, key string) error {
+	defer s.slotLocks.lock(entry)()
+
+	raw, err := s.haloDB.Get(s.slotPosKey(entry, key))
+	if err != nil {
+		return nil
+	}
+	i, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid slot of %s", key)
+	}
+	last := s.slotLen(entry) - 1
+	if i < last {
+		lk, err := s.haloDB.Get(s.slotKey(entry, last))
+		if err != nil {
+			return err
+		}
+		err = s.haloDB.Put(s.slotKey(entry, i), lk)
+		if err != nil {
+			return err
+		}
+		err = s.haloDB.Put(s.slotPosKey(entry, lk), raw)
+		if err != nil {
+			return err
+		}
+	}
+	if last >= 0 {
+		err = s.haloDB.Delete(s.slotKey(entry, last))
+		if err != nil {
+			return err
+		}
+	}
+	err = s.haloDB.Delete(s.slotPosKey(entry, key))
+	if err != nil {
+		return err
+	}
+	if last <= 0 {
+		return s.haloDB.Delete(entry)
+	}
+	return s.haloDB.Put(entry, strconv.FormatInt(last, 10))
+}
+
+// slotKeys returns the keys of the entry, in no particular order.
+func (s *server) slotKeys(entry string) []string {
+	unlock := s.slotLocks.rlock(entry)
+	defer unlock()
+
+	n := s.slotLen(entry)
+	keys := make([]string, 0, n)
+	for i := int64(0); i < n; i++ {
+		key, err := s.haloDB.Get(s.slotKey(entry, i))
+		if err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/safety"
+	"github.com/vdaas/vald/apis/grpc/meta"
+)
+
+// trashSweepable is a server or the namespaces, whose trash entries are purged by a TrashSweeper.
+type trashSweepable interface {
+	sweepTrash(now time.Time, retention time.Duration) (uint64, error)
+}
+
+type trashSweeper struct {
+	s         trashSweepable
+	dur       time.Duration
+	retention time.Duration
+	purged    uint64
+	eg        errgroup.Group
+}
+
+// TrashSweeper purges the trash entries older than the retention period.
+type TrashSweeper interface {
+	Start(ctx context.Context) <-chan error
+	// Purged returns the cumulative count of the purged entries.
+	Purged() uint64
+}
+
+// NewTrashSweeper returns the sweeper of the trash of g, which must be a server returned by New.
+func NewTrashSweeper(g meta.MetaServer, opts ...TrashSweeperOption) (TrashSweeper, error) {
+	s, ok := g.(trashSweepable)
+	if !ok {
+		return nil, errors.New("the meta server has no trash")
+	}
+	t := &trashSweeper{
+		s: s,
+	}
+
+	for _, opt := range append(defaultTrashSweeperOpts, opts...) {
+		if err := opt(t); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (t *trashSweeper) Start(ctx context.Context) <-chan error {
+	ech := make(chan error, 1)
+	t.eg.Go(safety.RecoverFunc(func() error {
+		defer close(ech)
+		tick := time.NewTicker(t.dur)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case now := <-tick.C:
+				n, err := t.s.sweepTrash(now, t.retention)
+				atomic.AddUint64(&t.purged, n)
+				if err != nil {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case ech <- err:
+					}
+				}
+			}
+		}
+	}))
+	return ech
+}
+
+func (t *trashSweeper) Purged() uint64 {
+	return atomic.LoadUint64(&t.purged)
+}
@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/timeutil"
+)
+
+type TrashSweeperOption func(*trashSweeper) error
+
+var (
+	defaultTrashSweeperOpts = []TrashSweeperOption{
+		WithTrashSweepDuration("1m"),
+		WithTrashRetention("168h"),
+		WithTrashSweeperErrGroup(errgroup.Get()),
+	}
+)
+
+func WithTrashSweepDuration(dur string) TrashSweeperOption {
+	return func(t *trashSweeper) error {
+		if len(dur) == 0 {
+			return nil
+		}
+		d, err := timeutil.Parse(dur)
+		if err != nil {
+			return err
+		}
+		t.dur = d
+		return nil
+	}
+}
+
+// WithTrashRetention sets how long the deleted entries are kept in the trash.
+func WithTrashRetention(dur string) TrashSweeperOption {
+	return func(t *trashSweeper) error {
+		if len(dur) == 0 {
+			return nil
+		}
+		d, err := timeutil.Parse(dur)
+		if err != nil {
+			return err
+		}
+		t.retention = d
+		return nil
+	}
+}
+
+func WithTrashSweeperErrGroup(eg errgroup.Group) TrashSweeperOption {
+	return func(t *trashSweeper) error {
+		if eg != nil {
+			t.eg = eg
+		}
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/info"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc/status"
+	"github.com/rinx/vald-meta-halodb/internal/observability/trace"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	errEmptyKey           = errors.New("empty key")
+	errKeyNotFound        = errors.New("key not found")
+	errTransactionAborted = errors.New("another operation of the transaction failed")
+)
+
+// txDB buffers the writes of a transaction, reads see the buffered writes first.
+type txDB struct {
+	service.HaloDB
+	ms []service.Mutation
+	// latest is the position of the last mutation of each key in ms
+	latest map[string]int
+}
+
+func newTxDB(h service.HaloDB) *txDB {
+	return &txDB{
+		HaloDB: h,
+		latest: make(map[string]int),
+	}
+}
+
+func (t *txDB) Get(key string) (string, error) {
+	if i, ok := t.latest[key]; ok {
+		if t.ms[i].Delete {
+			return "", errors.Errorf("failed to get %s", key)
+		}
+		return t.ms[i].Value, nil
+	}
+	return t.HaloDB.Get(key)
+}
+
+func (t *txDB) Put(key, value string) error {
+	t.latest[key] = len(t.ms)
+	t.ms = append(t.ms, service.Mutation{
+		Key:   key,
+		Value: value,
+	})
+	return nil
+}
+
+func (t *txDB) Delete(key string) error {
+	if _, err := t.Get(key); err != nil {
+		return err
+	}
+	t.latest[key] = len(t.ms)
+	t.ms = append(t.ms, service.Mutation{
+		Key:    key,
+		Delete: true,
+	})
+	return nil
+}
+
+// mutations returns the last mutation of each key in order.
+func (t *txDB) mutations() []service.Mutation {
+	ms := make([]service.Mutation, 0, len(t.latest))
+	for i, m := range t.ms {
+		if t.latest[m.Key] == i {
+			ms = append(ms, m)
+		}
+	}
+	return ms
+}
+
+// check returns the error of the conditions of op against the current entries.
+// It must be called with s.mu held, on the server of the transaction to see the previous operations.
+func (s *server) check(op *extension.TransactionOperation) error {
+	if len(op.GetKey()) == 0 {
+		return errEmptyKey
+	}
+	if !op.GetDelete() && s.jsonValues && !json.Valid([]byte(op.GetVal())) {
+		return errInvalidJSON
+	}
+	if op.GetExpectedVersion() != 0 && !s.versioning {
+		return errVersioningDisabled
+	}
+	cur, err := s.haloDB.Get(s.kvKey(op.GetKey()))
+	found := err == nil
+	if op.GetIfAbsent() && found {
+		return errKeyAlreadyExists
+	}
+	if len(op.GetExpected()) != 0 && (!found || cur != op.GetExpected()) {
+		return errConditionFailed
+	}
+	if op.GetExpectedVersion() != 0 && (!found || s.entryVersion(op.GetKey()) != op.GetExpectedVersion()) {
+		return errVersionMismatch
+	}
+	return nil
+}
+
+func transactionCode(err error) codes.Code {
+	switch err {
+	case nil:
+		return codes.OK
+	case errEmptyKey, errInvalidJSON, errVersioningDisabled:
+		return codes.InvalidArgument
+	case errKeyAlreadyExists, errValueAlreadyExists:
+		return codes.AlreadyExists
+	case errConditionFailed, errVersionMismatch:
+		return codes.FailedPrecondition
+	case errKeyNotFound:
+		return codes.NotFound
+	case errQuotaExceeded:
+		return codes.ResourceExhausted
+	case errTransactionAborted:
+		return codes.Aborted
+	}
+	return codes.Unknown
+}
+
+// transaction applies the operations in order to a txDB, and commits its mutations at once.
+// Each operation is checked just before it is applied, so its conditions and the operation itself
+// see the writes of the previous ones, e.g. a key set by the transaction can be expected afterwards.
+// The operations after the first failed one are not checked and are reported as aborted.
+func (s *server) transaction(ops []*extension.TransactionOperation) (*extension.TransactionResponse, error) {
+	res := &extension.TransactionResponse{
+		Results: make([]*extension.TransactionResult, 0, len(ops)),
+	}
+	errs := make([]error, len(ops))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failed := false
+	tx := newTxDB(s.haloDB)
+	txs := &server{
+		haloDB:       tx,
+		policy:       s.policy,
+		jsonValues:   s.jsonValues,
+		indexFields:  s.indexFields,
+		ns:           s.ns,
+		quota:        s.quota,
+		historySize:  s.historySize,
+		versioning:   s.versioning,
+		trash:        s.trash,
+		trashPending: s.trashPending,
+	}
+	for i, op := range ops {
+		r := &extension.TransactionResult{
+			Key: op.GetKey(),
+		}
+		res.Results = append(res.Results, r)
+		if failed {
+			continue
+		}
+		errs[i] = txs.check(op)
+		if errs[i] != nil {
+			failed = true
+			continue
+		}
+		if op.GetDelete() {
+			var err error
+			r.Val, err = txs.haloDB.Get(txs.kvKey(op.GetKey()))
+			if err != nil {
+				errs[i] = errKeyNotFound
+			} else {
+				errs[i] = txs.deleteLocked(op.GetKey(), r.Val)
+			}
+		} else {
+			r.Version, errs[i] = txs.setLocked(op.GetKey(), op.GetVal(), 0, nil)
+		}
+		failed = errs[i] != nil
+	}
+
+	if !failed {
+		err := service.Commit(s.haloDB, tx.mutations())
+		if err != nil {
+			return nil, err
+		}
+		s.trashPending = s.trashPending || txs.trashPending
+		res.Committed = true
+		return res, nil
+	}
+
+	for i, r := range res.Results {
+		err := errs[i]
+		if err == nil {
+			err = errTransactionAborted
+		}
+		r.Val, r.Version = "", 0
+		r.Code = uint32(transactionCode(err))
+		r.Message = err.Error()
+	}
+	return res, nil
+}
+
+func (s *server) Transaction(ctx context.Context, req *extension.TransactionRequest) (*extension.TransactionResponse, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.Transaction")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	res, err := s.transaction(req.GetOperations())
+	if err == service.ErrTransactionUnsupported {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeFailedPrecondition(err.Error()))
+		}
+		return nil, status.WrapWithFailedPrecondition("Transaction API haloDB transactions are not enabled", err, info.Get())
+	}
+	if err != nil {
+		log.Errorf("[Transaction]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInternal(err.Error()))
+		}
+		return nil, status.WrapWithInternal(fmt.Sprintf("Transaction API haloDB %d operations failed to commit", len(req.GetOperations())), err, info.Get())
+	}
+	return res, nil
+}
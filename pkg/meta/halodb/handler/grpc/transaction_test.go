@@ -0,0 +1,210 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/payload"
+	"google.golang.org/grpc/codes"
+)
+
+func Test_server_Transaction(t *testing.T) {
+	type args struct {
+		ops []*extension.TransactionOperation
+	}
+	type fields struct {
+		kvs  []*payload.Meta_KeyVal
+		opts []Option
+	}
+	type want struct {
+		committed bool
+		codes     []codes.Code
+		kvs       map[string]string
+		commits   int
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, *extension.TransactionResponse, *memDB) error
+	}
+	defaultCheckFunc := func(w want, res *extension.TransactionResponse, db *memDB) error {
+		if res.GetCommitted() != w.committed {
+			return errors.Errorf("got committed = %v, want %v", res.GetCommitted(), w.committed)
+		}
+		cs := make([]codes.Code, 0, len(res.GetResults()))
+		for _, r := range res.GetResults() {
+			cs = append(cs, codes.Code(r.GetCode()))
+		}
+		if !reflect.DeepEqual(cs, w.codes) {
+			return errors.Errorf("got codes = %v, want %v", cs, w.codes)
+		}
+		kvs := make(map[string]string)
+		for k, v := range db.entries() {
+			if strings.HasPrefix(k, "kv:") {
+				kvs[strings.TrimPrefix(k, "kv:")] = v
+			}
+		}
+		if !reflect.DeepEqual(kvs, w.kvs) {
+			return errors.Errorf("got entries = %v, want %v", kvs, w.kvs)
+		}
+		if db.commits != w.commits {
+			return errors.Errorf("got commits = %d, want %d", db.commits, w.commits)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the condition of an operation sees the value set by a previous one",
+			args: args{
+				ops: []*extension.TransactionOperation{
+					{Key: "a", Val: "1"},
+					{Key: "a", Val: "2", Expected: "1"},
+				},
+			},
+			want: want{
+				committed: true,
+				codes:     []codes.Code{codes.OK, codes.OK},
+				kvs: map[string]string{
+					"a": "2",
+				},
+				commits: 1,
+			},
+		},
+		{
+			name: "the condition of an operation sees the key deleted by a previous one",
+			args: args{
+				ops: []*extension.TransactionOperation{
+					{Key: "a", Delete: true},
+					{Key: "a", Val: "2", IfAbsent: true},
+				},
+			},
+			fields: fields{
+				kvs: []*payload.Meta_KeyVal{
+					{Key: "a", Val: "1"},
+				},
+			},
+			want: want{
+				committed: true,
+				codes:     []codes.Code{codes.OK, codes.OK},
+				kvs: map[string]string{
+					"a": "2",
+				},
+				commits: 1,
+			},
+		},
+		{
+			name: "a key set by a previous operation is not absent",
+			args: args{
+				ops: []*extension.TransactionOperation{
+					{Key: "a", Val: "1"},
+					{Key: "a", Val: "2", IfAbsent: true},
+				},
+			},
+			want: want{
+				codes: []codes.Code{codes.Aborted, codes.AlreadyExists},
+				kvs:   map[string]string{},
+			},
+		},
+		{
+			name: "a failed condition applies nothing and aborts the other operations",
+			args: args{
+				ops: []*extension.TransactionOperation{
+					{Key: "c", Val: "3"},
+					{Key: "a", Val: "2", Expected: "0"},
+					{Key: "b", Delete: true},
+				},
+			},
+			fields: fields{
+				kvs: []*payload.Meta_KeyVal{
+					{Key: "a", Val: "1"},
+					{Key: "b", Val: "1"},
+				},
+			},
+			want: want{
+				codes: []codes.Code{codes.Aborted, codes.FailedPrecondition, codes.Aborted},
+				kvs: map[string]string{
+					"a": "1",
+					"b": "1",
+				},
+			},
+		},
+		{
+			name: "a failed operation applies nothing",
+			args: args{
+				ops: []*extension.TransactionOperation{
+					{Key: "a", Val: "2"},
+					{Key: "c", Delete: true},
+				},
+			},
+			fields: fields{
+				kvs: []*payload.Meta_KeyVal{
+					{Key: "a", Val: "1"},
+				},
+			},
+			want: want{
+				codes: []codes.Code{codes.Aborted, codes.NotFound},
+				kvs: map[string]string{
+					"a": "1",
+				},
+			},
+		},
+		{
+			name: "a value moved within a transaction does not conflict under the reject policy",
+			args: args{
+				ops: []*extension.TransactionOperation{
+					{Key: "a", Delete: true},
+					{Key: "b", Val: "1"},
+				},
+			},
+			fields: fields{
+				kvs: []*payload.Meta_KeyVal{
+					{Key: "a", Val: "1"},
+				},
+				opts: []Option{
+					WithUniquenessPolicy(RejectPolicy),
+				},
+			},
+			want: want{
+				committed: true,
+				codes:     []codes.Code{codes.OK, codes.OK},
+				kvs: map[string]string{
+					"b": "1",
+				},
+				commits: 1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			ctx := context.Background()
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := newMemDB(nil)
+			s := New(append([]Option{WithHaloDB(db)}, test.fields.opts...)...).(*server)
+			for _, kv := range test.fields.kvs {
+				if _, err := s.SetMeta(ctx, kv); err != nil {
+					tt.Fatal(err)
+				}
+			}
+
+			res, err := s.Transaction(ctx, &extension.TransactionRequest{
+				Operations: test.args.ops,
+			})
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err := checkFunc(test.want, res, db); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
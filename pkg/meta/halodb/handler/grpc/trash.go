@@ -0,0 +1,368 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/info"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/internal/net/grpc/status"
+	"github.com/rinx/vald-meta-halodb/internal/observability/trace"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/payload"
+)
+
+const (
+	// trashBucketSize is the time span of the deletions listed in a trash bucket.
+	trashBucketSize = int64(time.Minute)
+	// trashFirstKey and trashLastKey hold the oldest and the latest trash buckets.
+	trashFirstKey = "tb:first"
+	trashLastKey  = "tb:last"
+)
+
+var (
+	errTrashDisabled = errors.New("trash is not enabled")
+	errNotInTrash    = errors.New("key not found in trash")
+)
+
+// trashEntry is a deleted value, DeletedAt is when it was deleted in unix nanoseconds.
+type trashEntry struct {
+	Val       string `json:"val"`
+	DeletedAt int64  `json:"deleted_at"`
+}
+
+// trKey holds the trash entry of the key.
+func (s *server) trKey(key string) string {
+	return "tr:" + key
+}
+
+// tbKey holds the keys deleted in the bucket in slots.
+// HaloDB cannot list keys, so the trash is walked through the buckets between the first and the last one.
+func (s *server) tbKey(bucket int64) string {
+	return "tb:" + strconv.FormatInt(bucket, 10)
+}
+
+func trashBucket(ts int64) int64 {
+	return ts - ts%trashBucketSize
+}
+
+func (s *server) trashEntry(key string) (*trashEntry, error) {
+	raw, err := s.haloDB.Get(s.trKey(key))
+	if err != nil {
+		return nil, err
+	}
+	e := new(trashEntry)
+	err = json.Unmarshal([]byte(raw), e)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid trash entry of %s", key)
+	}
+	return e, nil
+}
+
+func (s *server) trashBound(key string) (int64, bool) {
+	raw, err := s.haloDB.Get(key)
+	if err != nil {
+		return 0, false
+	}
+	b, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return b, true
+}
+
+// moveToTrash records val as the trash entry of key, replacing the one of a previous deletion.
+// It must be called with the entry locked, before the entry is deleted.
+func (s *server) moveToTrash(key, val string) error {
+	s.trashMu.Lock()
+	defer s.trashMu.Unlock()
+
+	if old, err := s.trashEntry(key); err == nil {
+		s.removeTrashKey(trashBucket(old.DeletedAt), key)
+	}
+	now := time.Now().UnixNano()
+	raw, err := json.Marshal(trashEntry{
+		Val:       val,
+		DeletedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	err = s.haloDB.Put(s.trKey(key), string(raw))
+	if err != nil {
+		return err
+	}
+	b := trashBucket(now)
+	err = s.addSlotKey(s.tbKey(b), key)
+	if err != nil {
+		return err
+	}
+	if first, ok := s.trashBound(trashFirstKey); !ok || b < first {
+		err = s.haloDB.Put(trashFirstKey, strconv.FormatInt(b, 10))
+		if err != nil {
+			return err
+		}
+	}
+	if last, ok := s.trashBound(trashLastKey); !ok || b > last {
+		err = s.haloDB.Put(trashLastKey, strconv.FormatInt(b, 10))
+		if err != nil {
+			return err
+		}
+	}
+	s.trashPending = true
+	return nil
+}
+
+// removeTrashKey drops key from the bucket, it must be called with s.trashMu held.
+func (s *server) removeTrashKey(bucket int64, key string) {
+	err := s.removeSlotKey(s.tbKey(bucket), key)
+	if err != nil {
+		log.Warnf("[Trash]\tfailed to remove %s from the trash bucket %d\t%+v", key, bucket, err)
+	}
+}
+
+// trashBucketKeys calls fn with the keys whose latest deletion is in the bucket, oldest first, until fn returns false.
+// It reports whether all the keys were passed, it must be called with s.trashMu held.
+func (s *server) trashBucketKeys(bucket int64, fn func(key string, e *trashEntry) bool) bool {
+	type item struct {
+		key string
+		e   *trashEntry
+	}
+	keys := s.slotKeys(s.tbKey(bucket))
+	items := make([]item, 0, len(keys))
+	for _, key := range keys {
+		e, err := s.trashEntry(key)
+		if err != nil || trashBucket(e.DeletedAt) != bucket {
+			continue
+		}
+		items = append(items, item{
+			key: key,
+			e:   e,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].e.DeletedAt != items[j].e.DeletedAt {
+			return items[i].e.DeletedAt < items[j].e.DeletedAt
+		}
+		return items[i].key < items[j].key
+	})
+	for _, it := range items {
+		if !fn(it.key, it.e) {
+			return false
+		}
+	}
+	return true
+}
+
+// listTrash returns up to limit trash entries oldest first, all of them when limit is 0.
+func (s *server) listTrash(limit int) ([]*extension.TrashEntry, error) {
+	if !s.trash {
+		return nil, errTrashDisabled
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.trashMu.Lock()
+	defer s.trashMu.Unlock()
+
+	res := make([]*extension.TrashEntry, 0)
+	first, ok := s.trashBound(trashFirstKey)
+	if !ok {
+		return res, nil
+	}
+	last, _ := s.trashBound(trashLastKey)
+	for b := first; b <= last; b += trashBucketSize {
+		more := s.trashBucketKeys(b, func(key string, e *trashEntry) bool {
+			res = append(res, &extension.TrashEntry{
+				Key:       key,
+				Val:       e.Val,
+				DeletedAt: e.DeletedAt,
+			})
+			return limit <= 0 || len(res) < limit
+		})
+		if !more {
+			break
+		}
+	}
+	return res, nil
+}
+
+// restore sets the trash entry of key again under the uniqueness policy and returns its value.
+// It fails when the key has been set since it was deleted, or under the reject and overwrite policies
+// when another key holds the value, so a restore never removes a live entry.
+func (s *server) restore(key string) (string, error) {
+	if !s.trash {
+		return "", errTrashDisabled
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.trashEntry(key)
+	if err != nil {
+		return "", errNotInTrash
+	}
+	_, err = s.setLocked(key, e.Val, 0, func(cur string, found bool) error {
+		if found {
+			return errKeyAlreadyExists
+		}
+		if s.policy == RejectPolicy || s.policy == OverwritePolicy {
+			owner, err := s.haloDB.Get(s.vkKey(e.Val))
+			if err == nil && owner != key && s.owns(owner, e.Val) {
+				return errValueAlreadyExists
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	err = s.haloDB.Delete(s.trKey(key))
+	if err != nil {
+		log.Warnf("[Trash]\tfailed to delete the trash entry of restored %s\t%+v", key, err)
+	}
+	s.trashMu.Lock()
+	s.removeTrashKey(trashBucket(e.DeletedAt), key)
+	s.trashMu.Unlock()
+	return e.Val, nil
+}
+
+// sweepTrash purges the trash entries deleted more than retention before now and returns their number.
+// The buckets are purged whole, so an entry is kept at most one bucket longer than retention.
+// The trash is locked for one bucket at a time, so the deletes wait for the purge of one bucket at most.
+func (s *server) sweepTrash(now time.Time, retention time.Duration) (purged uint64, errs error) {
+	for {
+		n, more, err := s.sweepTrashBucket(now, retention)
+		purged += n
+		if err != nil {
+			errs = errors.Wrap(errs, err.Error())
+		}
+		if !more {
+			return purged, errs
+		}
+	}
+}
+
+// sweepTrashBucket purges the oldest bucket when it is due, and reports whether the next one may be due.
+// A bucket whose entries could not all be purged is kept and walked again by the next sweep.
+func (s *server) sweepTrashBucket(now time.Time, retention time.Duration) (purged uint64, more bool, errs error) {
+	// restore holds s.mu exclusively, so it does not interleave the purge of its entry
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.trashMu.Lock()
+	defer s.trashMu.Unlock()
+
+	if !s.trashPending {
+		return 0, false, nil
+	}
+	first, ok := s.trashBound(trashFirstKey)
+	if !ok {
+		s.trashPending = false
+		return 0, false, nil
+	}
+	if first+trashBucketSize+int64(retention) > now.UnixNano() {
+		return 0, false, nil
+	}
+	entry := s.tbKey(first)
+	for _, key := range s.slotKeys(entry) {
+		if e, err := s.trashEntry(key); err == nil && trashBucket(e.DeletedAt) == first {
+			err = s.haloDB.Delete(s.trKey(key))
+			if err != nil {
+				log.Warnf("[Trash]\tfailed to purge %s\t%+v", key, err)
+				errs = errors.Wrap(errs, err.Error())
+				continue
+			}
+			purged++
+		}
+		if err := s.removeSlotKey(entry, key); err != nil {
+			errs = errors.Wrap(errs, err.Error())
+		}
+	}
+	if errs != nil {
+		return purged, false, errs
+	}
+	if last, _ := s.trashBound(trashLastKey); first < last {
+		err := s.haloDB.Put(trashFirstKey, strconv.FormatInt(first+trashBucketSize, 10))
+		if err != nil {
+			return purged, false, err
+		}
+		return purged, true, nil
+	}
+	s.trashPending = false
+	for _, k := range []string{trashFirstKey, trashLastKey} {
+		if err := s.haloDB.Delete(k); err != nil {
+			errs = errors.Wrap(errs, err.Error())
+		}
+	}
+	return purged, false, errs
+}
+
+func (s *server) ListTrash(ctx context.Context, req *extension.TrashListRequest) (*extension.TrashEntries, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.ListTrash")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	es, err := s.listTrash(int(req.GetLimit()))
+	if err == errTrashDisabled {
+		if span != nil {
+			span.SetStatus(trace.StatusCodeFailedPrecondition(err.Error()))
+		}
+		return nil, status.WrapWithFailedPrecondition("ListTrash API haloDB trash is not enabled", err, info.Get())
+	}
+	if err != nil {
+		log.Errorf("[ListTrash]\tunknown error\t%+v", err)
+		if span != nil {
+			span.SetStatus(trace.StatusCodeInternal(err.Error()))
+		}
+		return nil, status.WrapWithInternal("ListTrash API haloDB failed to list the trash", err, info.Get())
+	}
+	return &extension.TrashEntries{
+		Entries: es,
+	}, nil
+}
+
+func (s *server) RestoreMeta(ctx context.Context, key *payload.Meta_Key) (*payload.Meta_Val, error) {
+	ctx, span := trace.StartSpan(ctx, "vald/meta-haloDB.RestoreMeta")
+	defer func() {
+		if span != nil {
+			span.End()
+		}
+	}()
+	val, err := s.restore(key.GetKey())
+	switch err {
+	case nil:
+		return &payload.Meta_Val{
+			Val: val,
+		}, nil
+	case errTrashDisabled:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeFailedPrecondition(err.Error()))
+		}
+		return nil, status.WrapWithFailedPrecondition(fmt.Sprintf("RestoreMeta API haloDB key %s trash is not enabled", key.GetKey()), err, info.Get())
+	case errNotInTrash:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeNotFound(err.Error()))
+		}
+		return nil, status.WrapWithNotFound(fmt.Sprintf("RestoreMeta API haloDB key %s not found in trash", key.GetKey()), err, info.Get())
+	case errKeyAlreadyExists, errValueAlreadyExists:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeAlreadyExists(err.Error()))
+		}
+		return nil, status.WrapWithAlreadyExists(fmt.Sprintf("RestoreMeta API haloDB key %s already exists", key.GetKey()), err, info.Get())
+	case errQuotaExceeded:
+		if span != nil {
+			span.SetStatus(trace.StatusCodeResourceExhausted(err.Error()))
+		}
+		return nil, status.WrapWithResourceExhausted(fmt.Sprintf("RestoreMeta API haloDB key %s namespace quota exceeded", key.GetKey()), err, info.Get())
+	}
+	log.Errorf("[RestoreMeta]\tunknown error\t%+v", err)
+	if span != nil {
+		span.SetStatus(trace.StatusCodeInternal(err.Error()))
+	}
+	return nil, status.WrapWithInternal(fmt.Sprintf("RestoreMeta API haloDB key %s failed to restore", key.GetKey()), err, info.Get())
+}
@@ -0,0 +1,444 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/payload"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// trashOp is a step of a trash test, exactly one of its fields is set.
+type trashOp struct {
+	set     *payload.Meta_KeyVal
+	del     string
+	restore string
+}
+
+// bulkTrashOps sets the keys k0 to k<n-1> and then deletes them.
+func bulkTrashOps(n int) []trashOp {
+	ops := make([]trashOp, 0, 2*n)
+	for i := 0; i < n; i++ {
+		ops = append(ops, trashOp{
+			set: &payload.Meta_KeyVal{Key: "k" + strconv.Itoa(i), Val: "v" + strconv.Itoa(i)},
+		})
+	}
+	for i := 0; i < n; i++ {
+		ops = append(ops, trashOp{
+			del: "k" + strconv.Itoa(i),
+		})
+	}
+	return ops
+}
+
+// runTrashOps runs the ops and returns the codes of the restores.
+func runTrashOps(ctx context.Context, s *server, ops []trashOp) ([]codes.Code, error) {
+	var cs []codes.Code
+	for _, op := range ops {
+		switch {
+		case op.set != nil:
+			if _, err := s.SetMeta(ctx, op.set); err != nil {
+				return nil, err
+			}
+		case len(op.del) != 0:
+			if _, err := s.DeleteMeta(ctx, &payload.Meta_Key{Key: op.del}); err != nil {
+				return nil, err
+			}
+		default:
+			_, err := s.RestoreMeta(ctx, &payload.Meta_Key{Key: op.restore})
+			cs = append(cs, status.Code(err))
+		}
+	}
+	return cs, nil
+}
+
+// trashState returns the entries, the inverse entries and the keys in the trash.
+func trashState(ctx context.Context, s *server, db *memDB) (kvs, vks map[string]string, trash []string) {
+	kvs = make(map[string]string)
+	vks = make(map[string]string)
+	for k, v := range db.entries() {
+		switch {
+		case strings.HasPrefix(k, "kv:"):
+			kvs[strings.TrimPrefix(k, "kv:")] = v
+		case strings.HasPrefix(k, "vk:"):
+			vks[strings.TrimPrefix(k, "vk:")] = v
+		}
+	}
+	if l, err := s.ListTrash(ctx, &extension.TrashListRequest{}); err == nil {
+		for _, e := range l.GetEntries() {
+			trash = append(trash, e.GetKey())
+		}
+	}
+	return kvs, vks, trash
+}
+
+func Test_server_RestoreMeta(t *testing.T) {
+	type args struct {
+		ops []trashOp
+	}
+	type fields struct {
+		opts []Option
+	}
+	type want struct {
+		codes []codes.Code
+		kvs   map[string]string
+		vks   map[string]string
+		trash []string
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, []codes.Code, *server, *memDB) error
+	}
+	defaultCheckFunc := func(w want, cs []codes.Code, s *server, db *memDB) error {
+		if !reflect.DeepEqual(cs, w.codes) {
+			return errors.Errorf("got codes = %v, want %v", cs, w.codes)
+		}
+		kvs, vks, trash := trashState(context.Background(), s, db)
+		if !reflect.DeepEqual(kvs, w.kvs) {
+			return errors.Errorf("got entries = %v, want %v", kvs, w.kvs)
+		}
+		if !reflect.DeepEqual(vks, w.vks) {
+			return errors.Errorf("got inverse entries = %v, want %v", vks, w.vks)
+		}
+		if !reflect.DeepEqual(trash, w.trash) {
+			return errors.Errorf("got trash = %v, want %v", trash, w.trash)
+		}
+		return nil
+	}
+	trashOpts := []Option{
+		WithTrash(true),
+		WithUniquenessPolicy(RejectPolicy),
+	}
+	tests := []test{
+		{
+			name: "a deleted entry is hidden in the trash",
+			args: args{
+				ops: []trashOp{
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "va"}},
+					{set: &payload.Meta_KeyVal{Key: "b", Val: "vb"}},
+					{del: "a"},
+					{del: "b"},
+				},
+			},
+			fields: fields{
+				opts: trashOpts,
+			},
+			want: want{
+				kvs:   map[string]string{},
+				vks:   map[string]string{},
+				trash: []string{"a", "b"},
+			},
+		},
+		{
+			name: "a bulk delete is listed oldest first without the restored entries",
+			args: args{
+				ops: append(bulkTrashOps(4),
+					trashOp{restore: "k1"}),
+			},
+			fields: fields{
+				opts: trashOpts,
+			},
+			want: want{
+				codes: []codes.Code{codes.OK},
+				kvs: map[string]string{
+					"k1": "v1",
+				},
+				vks: map[string]string{
+					"v1": "k1",
+				},
+				trash: []string{"k0", "k2", "k3"},
+			},
+		},
+		{
+			name: "a deleted entry is restored with its inverse entry",
+			args: args{
+				ops: []trashOp{
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "va"}},
+					{del: "a"},
+					{restore: "a"},
+				},
+			},
+			fields: fields{
+				opts: trashOpts,
+			},
+			want: want{
+				codes: []codes.Code{codes.OK},
+				kvs: map[string]string{
+					"a": "va",
+				},
+				vks: map[string]string{
+					"va": "a",
+				},
+			},
+		},
+		{
+			name: "a restored entry is not restored again",
+			args: args{
+				ops: []trashOp{
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "va"}},
+					{del: "a"},
+					{restore: "a"},
+					{restore: "a"},
+				},
+			},
+			fields: fields{
+				opts: trashOpts,
+			},
+			want: want{
+				codes: []codes.Code{codes.OK, codes.NotFound},
+				kvs: map[string]string{
+					"a": "va",
+				},
+				vks: map[string]string{
+					"va": "a",
+				},
+			},
+		},
+		{
+			name: "a key set again after the delete is not overwritten by a restore",
+			args: args{
+				ops: []trashOp{
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "va"}},
+					{del: "a"},
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "vb"}},
+					{restore: "a"},
+				},
+			},
+			fields: fields{
+				opts: trashOpts,
+			},
+			want: want{
+				codes: []codes.Code{codes.AlreadyExists},
+				kvs: map[string]string{
+					"a": "vb",
+				},
+				vks: map[string]string{
+					"vb": "a",
+				},
+				trash: []string{"a"},
+			},
+		},
+		{
+			name: "a value taken by another key is not restored under the reject policy",
+			args: args{
+				ops: []trashOp{
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "v"}},
+					{del: "a"},
+					{set: &payload.Meta_KeyVal{Key: "b", Val: "v"}},
+					{restore: "a"},
+				},
+			},
+			fields: fields{
+				opts: trashOpts,
+			},
+			want: want{
+				codes: []codes.Code{codes.AlreadyExists},
+				kvs: map[string]string{
+					"b": "v",
+				},
+				vks: map[string]string{
+					"v": "b",
+				},
+				trash: []string{"a"},
+			},
+		},
+		{
+			name: "a restore fails when the trash is disabled",
+			args: args{
+				ops: []trashOp{
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "va"}},
+					{del: "a"},
+					{restore: "a"},
+				},
+			},
+			want: want{
+				codes: []codes.Code{codes.FailedPrecondition},
+				kvs:   map[string]string{},
+				// without the trash a delete leaves the inverse entry as it always did
+				vks: map[string]string{
+					"va": "a",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			ctx := context.Background()
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := newMemDB(nil)
+			s := New(append([]Option{WithHaloDB(db)}, test.fields.opts...)...).(*server)
+
+			cs, err := runTrashOps(ctx, s, test.args.ops)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err := checkFunc(test.want, cs, s, db); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_server_sweepTrash(t *testing.T) {
+	type args struct {
+		ops       []trashOp
+		after     time.Duration
+		retention time.Duration
+	}
+	type want struct {
+		purged uint64
+		kvs    map[string]string
+		trash  []string
+	}
+	type test struct {
+		name      string
+		args      args
+		want      want
+		checkFunc func(want, uint64, *server, *memDB) error
+	}
+	defaultCheckFunc := func(w want, purged uint64, s *server, db *memDB) error {
+		if purged != w.purged {
+			return errors.Errorf("got purged = %v, want %v", purged, w.purged)
+		}
+		kvs, _, trash := trashState(context.Background(), s, db)
+		if !reflect.DeepEqual(kvs, w.kvs) {
+			return errors.Errorf("got entries = %v, want %v", kvs, w.kvs)
+		}
+		if !reflect.DeepEqual(trash, w.trash) {
+			return errors.Errorf("got trash = %v, want %v", trash, w.trash)
+		}
+		if len(w.trash) != 0 {
+			return nil
+		}
+		// an empty trash leaves neither the entries nor the buckets behind
+		for k := range db.entries() {
+			if strings.HasPrefix(k, "tr:") || strings.HasPrefix(k, "tb:") {
+				return errors.Errorf("got trash key %s after the sweep", k)
+			}
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the entries within the retention are kept",
+			args: args{
+				ops: []trashOp{
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "va"}},
+					{set: &payload.Meta_KeyVal{Key: "b", Val: "vb"}},
+					{del: "a"},
+					{del: "b"},
+				},
+				retention: time.Hour,
+			},
+			want: want{
+				kvs:   map[string]string{},
+				trash: []string{"a", "b"},
+			},
+		},
+		{
+			name: "the entries past the retention are purged",
+			args: args{
+				ops: []trashOp{
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "va"}},
+					{set: &payload.Meta_KeyVal{Key: "b", Val: "vb"}},
+					{del: "a"},
+					{del: "b"},
+				},
+				after:     2 * time.Hour,
+				retention: time.Hour,
+			},
+			want: want{
+				purged: 2,
+				kvs:    map[string]string{},
+			},
+		},
+		{
+			name: "a restored entry is not purged",
+			args: args{
+				ops: []trashOp{
+					{set: &payload.Meta_KeyVal{Key: "a", Val: "va"}},
+					{set: &payload.Meta_KeyVal{Key: "b", Val: "vb"}},
+					{del: "a"},
+					{del: "b"},
+					{restore: "a"},
+				},
+				after:     2 * time.Hour,
+				retention: time.Hour,
+			},
+			want: want{
+				purged: 1,
+				kvs: map[string]string{
+					"a": "va",
+				},
+			},
+		},
+		{
+			name: "a bulk delete is purged with the slots of its bucket",
+			args: args{
+				ops:       bulkTrashOps(100),
+				after:     2 * time.Hour,
+				retention: time.Hour,
+			},
+			want: want{
+				purged: 100,
+				kvs:    map[string]string{},
+			},
+		},
+		{
+			name: "the entries restored from a bulk delete are not purged",
+			args: args{
+				ops: append(bulkTrashOps(100),
+					trashOp{restore: "k0"},
+					trashOp{restore: "k50"},
+					trashOp{restore: "k99"}),
+				after:     2 * time.Hour,
+				retention: time.Hour,
+			},
+			want: want{
+				purged: 97,
+				kvs: map[string]string{
+					"k0":  "v0",
+					"k50": "v50",
+					"k99": "v99",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			ctx := context.Background()
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := newMemDB(nil)
+			s := New(WithHaloDB(db), WithTrash(true)).(*server)
+
+			if _, err := runTrashOps(ctx, s, test.args.ops); err != nil {
+				tt.Fatal(err)
+			}
+			purged, err := s.sweepTrash(time.Now().Add(test.args.after), test.args.retention)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err := checkFunc(test.want, purged, s, db); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
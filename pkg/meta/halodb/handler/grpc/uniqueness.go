@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+)
+
+// UniquenessPolicy decides what SetMeta does when the value is already owned by another key.
+type UniquenessPolicy string
+
+const (
+	// AllowPolicy lets several keys share a value, the inverse mapping points at the latest key.
+	AllowPolicy UniquenessPolicy = "allow"
+	// RejectPolicy fails the SetMeta with AlreadyExists.
+	RejectPolicy UniquenessPolicy = "reject"
+	// OverwritePolicy moves the value to the new key and deletes the entry of the old owner.
+	OverwritePolicy UniquenessPolicy = "overwrite"
+	// MultiPolicy lets several keys share a value, the inverse mapping keeps all of them.
+	MultiPolicy UniquenessPolicy = "multi"
+)
+
+var (
+	errValueAlreadyExists = errors.New("value is owned by another key")
+	errKeyAlreadyExists   = errors.New("key already exists")
+	errConditionFailed    = errors.New("current value does not match")
+	errInvalidJSON        = errors.New("value is not a valid JSON")
+	errQuotaExceeded      = errors.New("namespace quota exceeded")
+)
+
+func ParseUniquenessPolicy(p string) (UniquenessPolicy, error) {
+	switch UniquenessPolicy(p) {
+	case "", AllowPolicy:
+		return AllowPolicy, nil
+	case RejectPolicy, OverwritePolicy, MultiPolicy:
+		return UniquenessPolicy(p), nil
+	}
+	return "", errors.Errorf("invalid uniqueness policy %s", p)
+}
+
+// setMeta stores the entry following the uniqueness policy and returns its version, which is 0 without versioning.
+// cond is called with the current value of the key before anything is written.
+func (s *server) setMeta(key, val string, ttl time.Duration, cond func(cur string, found bool) error) (ver uint64, err error) {
+	if s.jsonValues && !json.Valid([]byte(val)) {
+		return 0, errInvalidJSON
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.policy == AllowPolicy && cond == nil && len(s.indexFields) == 0 && s.ns == nil && s.historySize <= 0 && !s.versioning {
+		defer s.locks.lock(s.kvKey(key), s.vkKey(val))()
+		return 0, s.put(key, val, ttl)
+	}
+
+	defer s.lockEntry(key, val)()
+	return s.setLocked(key, val, ttl, cond)
+}
+
+// setLocked is the body of setMeta, it must be called with the entry locked by lockEntry or s.mu held.
+func (s *server) setLocked(key, val string, ttl time.Duration, cond func(cur string, found bool) error) (ver uint64, err error) {
+	cur, err := s.haloDB.Get(s.kvKey(key))
+	found := err == nil
+	if cond != nil {
+		if err = cond(cur, found); err != nil {
+			return 0, err
+		}
+	}
+
+	if !found && s.ns != nil {
+		// the new entry is counted before it is written, so concurrent writes cannot exceed the quota
+		err = s.reserveCount()
+		if err != nil {
+			return 0, err
+		}
+		defer func() {
+			if err != nil {
+				s.addCount(-1)
+			}
+		}()
+	}
+
+	if s.versioning {
+		ver, err = s.nextVersion(key)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	switch s.policy {
+	case AllowPolicy:
+		err = s.put(key, val, ttl)
+	case MultiPolicy:
+		err = s.putMulti(key, val, ttl, cur, found)
+	default:
+		err = s.putUnique(key, val, ttl, cur, found)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if found {
+		s.updateIndexes(key, cur, val)
+	} else {
+		s.updateIndexes(key, "", val)
+	}
+	s.addVersion(key, val, ver)
+	return ver, nil
+}
+
+// putUnique stores the entry under the reject and overwrite policies.
+// It must be called with the entry locked.
+func (s *server) putUnique(key, val string, ttl time.Duration, cur string, found bool) error {
+	owner, err := s.haloDB.Get(s.vkKey(val))
+	if err == nil && owner != key && s.owns(owner, val) {
+		if s.policy == RejectPolicy {
+			return errValueAlreadyExists
+		}
+		err = s.haloDB.Delete(s.kvKey(owner))
+		if err != nil {
+			return err
+		}
+		s.updateIndexes(owner, val, "")
+		s.addCount(-1)
+	}
+
+	err = s.put(key, val, ttl)
+	if err != nil {
+		return err
+	}
+
+	// the previous value of the key must not resolve to it anymore
+	if found && cur != val {
+		owner, err := s.haloDB.Get(s.vkKey(cur))
+		if err == nil && owner == key {
+			err = s.haloDB.Delete(s.vkKey(cur))
+			if err != nil {
+				log.Warnf("[SetMeta]\tfailed to delete the inverse entry of the previous value of %s\t%+v", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// owns reports whether key still holds val, the inverse entries of the overwritten values may be stale.
+func (s *server) owns(key, val string) bool {
+	cur, err := s.haloDB.Get(s.kvKey(key))
+	return err == nil && cur == val
+}
+
+func (s *server) put(key, val string, ttl time.Duration) error {
+	if ttl > 0 {
+		return s.ttl.PutWithTTL(ttl, map[string]string{
+			s.kvKey(key): val,
+			s.vkKey(val): key,
+		})
+	}
+	err := s.haloDB.Put(s.kvKey(key), val)
+	if err != nil {
+		return err
+	}
+	return s.haloDB.Put(s.vkKey(val), key)
+}
@@ -0,0 +1,296 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/vdaas/vald/apis/grpc/meta"
+	"github.com/vdaas/vald/apis/grpc/payload"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uqOp is an operation of a uniqueness test, op is one of set, get and inv.
+type uqOp struct {
+	op  string
+	key string
+	val string
+}
+
+// uqResult is the code of an operation, with the value or key read by get or inv.
+type uqResult struct {
+	code codes.Code
+	val  string
+}
+
+func runUniquenessOps(s meta.MetaServer, ops []uqOp) []uqResult {
+	ctx := context.Background()
+	res := make([]uqResult, 0, len(ops))
+	for _, op := range ops {
+		var (
+			r   uqResult
+			err error
+		)
+		switch op.op {
+		case "set":
+			_, err = s.SetMeta(ctx, &payload.Meta_KeyVal{Key: op.key, Val: op.val})
+		case "get":
+			var v *payload.Meta_Val
+			v, err = s.GetMeta(ctx, &payload.Meta_Key{Key: op.key})
+			r.val = v.GetVal()
+		case "inv":
+			var k *payload.Meta_Key
+			k, err = s.GetMetaInverse(ctx, &payload.Meta_Val{Val: op.val})
+			r.val = k.GetKey()
+		}
+		r.code = status.Code(err)
+		res = append(res, r)
+	}
+	return res
+}
+
+func Test_server_SetMeta(t *testing.T) {
+	type args struct {
+		ops []uqOp
+	}
+	type fields struct {
+		opts []Option
+	}
+	type want struct {
+		results []uqResult
+		// kvs are the kv: and vk: entries after the operations
+		kvs map[string]string
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, []uqResult, map[string]string) error
+	}
+	defaultCheckFunc := func(w want, res []uqResult, entries map[string]string) error {
+		if !reflect.DeepEqual(res, w.results) {
+			return errors.Errorf("got results = %v, want %v", res, w.results)
+		}
+		kvs := make(map[string]string)
+		for k, v := range entries {
+			if strings.HasPrefix(k, "kv:") || strings.HasPrefix(k, "vk:") {
+				kvs[k] = v
+			}
+		}
+		if !reflect.DeepEqual(kvs, w.kvs) {
+			return errors.Errorf("got entries = %v, want %v", kvs, w.kvs)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the allow policy keeps both keys and points the inverse entry at the latest one",
+			args: args{
+				ops: []uqOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "b", val: "x"},
+					{op: "get", key: "a"},
+					{op: "inv", val: "x"},
+				},
+			},
+			fields: fields{
+				opts: []Option{WithUniquenessPolicy(AllowPolicy)},
+			},
+			want: want{
+				results: []uqResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "x"},
+					{code: codes.OK, val: "b"},
+				},
+				kvs: map[string]string{
+					"kv:a": "x",
+					"kv:b": "x",
+					"vk:x": "b",
+				},
+			},
+		},
+		{
+			name: "the allow policy without checks writes the entry and its inverse entry only",
+			args: args{
+				ops: []uqOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "get", key: "a"},
+					{op: "inv", val: "y"},
+				},
+			},
+			fields: fields{
+				opts: []Option{WithUniquenessPolicy(AllowPolicy)},
+			},
+			want: want{
+				results: []uqResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "y"},
+					{code: codes.OK, val: "a"},
+				},
+				kvs: map[string]string{
+					"kv:a": "y",
+					"vk:x": "a",
+					"vk:y": "a",
+				},
+			},
+		},
+		{
+			name: "the allow policy with versioning writes the same entries as without checks",
+			args: args{
+				ops: []uqOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "get", key: "a"},
+					{op: "inv", val: "y"},
+				},
+			},
+			fields: fields{
+				opts: []Option{WithUniquenessPolicy(AllowPolicy), WithVersioning(true)},
+			},
+			want: want{
+				results: []uqResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "y"},
+					{code: codes.OK, val: "a"},
+				},
+				kvs: map[string]string{
+					"kv:a": "y",
+					"vk:x": "a",
+					"vk:y": "a",
+				},
+			},
+		},
+		{
+			name: "the reject policy fails the value owned by another key",
+			args: args{
+				ops: []uqOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "b", val: "x"},
+					{op: "set", key: "a", val: "x"},
+					{op: "get", key: "b"},
+					{op: "inv", val: "x"},
+				},
+			},
+			fields: fields{
+				opts: []Option{WithUniquenessPolicy(RejectPolicy)},
+			},
+			want: want{
+				results: []uqResult{
+					{code: codes.OK},
+					{code: codes.AlreadyExists},
+					{code: codes.OK},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "a"},
+				},
+				kvs: map[string]string{
+					"kv:a": "x",
+					"vk:x": "a",
+				},
+			},
+		},
+		{
+			name: "the reject policy accepts the value released by its previous key",
+			args: args{
+				ops: []uqOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "set", key: "b", val: "x"},
+					{op: "inv", val: "x"},
+				},
+			},
+			fields: fields{
+				opts: []Option{WithUniquenessPolicy(RejectPolicy)},
+			},
+			want: want{
+				results: []uqResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.OK, val: "b"},
+				},
+				kvs: map[string]string{
+					"kv:a": "y",
+					"kv:b": "x",
+					"vk:x": "b",
+					"vk:y": "a",
+				},
+			},
+		},
+		{
+			name: "the overwrite policy deletes the entry of the previous owner",
+			args: args{
+				ops: []uqOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "b", val: "x"},
+					{op: "get", key: "a"},
+					{op: "inv", val: "x"},
+				},
+			},
+			fields: fields{
+				opts: []Option{WithUniquenessPolicy(OverwritePolicy)},
+			},
+			want: want{
+				results: []uqResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "b"},
+				},
+				kvs: map[string]string{
+					"kv:b": "x",
+					"vk:x": "b",
+				},
+			},
+		},
+		{
+			name: "the overwrite policy removes the stale inverse entry of the previous value",
+			args: args{
+				ops: []uqOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "inv", val: "x"},
+					{op: "inv", val: "y"},
+				},
+			},
+			fields: fields{
+				opts: []Option{WithUniquenessPolicy(OverwritePolicy)},
+			},
+			want: want{
+				results: []uqResult{
+					{code: codes.OK},
+					{code: codes.OK},
+					{code: codes.NotFound},
+					{code: codes.OK, val: "a"},
+				},
+				kvs: map[string]string{
+					"kv:a": "y",
+					"vk:y": "a",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := newMemDB(nil)
+			s := New(append([]Option{WithHaloDB(db)}, test.fields.opts...)...)
+
+			res := runUniquenessOps(s, test.args.ops)
+			if err := checkFunc(test.want, res, db.entries()); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
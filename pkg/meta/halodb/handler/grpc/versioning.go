@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	errVersionMismatch    = errors.New("current version does not match")
+	errVersioningDisabled = errors.New("versioning is not enabled")
+)
+
+// vrKey holds the version of the entry of the key.
+// It is kept after the key is deleted, so the versions never go back when the key is set again.
+func (s *server) vrKey(key string) string {
+	return "vr:" + key
+}
+
+// entryVersion returns the version of the key, 0 means it has never been set.
+func (s *server) entryVersion(key string) uint64 {
+	raw, err := s.haloDB.Get(s.vrKey(key))
+	if err != nil {
+		return 0
+	}
+	v, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// nextVersion stores and returns the version of the next write of the key.
+// It must be called with the entry locked before the write, a failed write only leaves a gap in the versions.
+func (s *server) nextVersion(key string) (uint64, error) {
+	v := s.entryVersion(key) + 1
+	err := s.haloDB.Put(s.vrKey(key), strconv.FormatUint(v, 10))
+	if err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// versionCond fails when the key does not exist or its version is not expected.
+// It is called with the entry locked, as the cond of setMeta.
+func (s *server) versionCond(key string, expected uint64) func(cur string, found bool) error {
+	return func(cur string, found bool) error {
+		if !found || s.entryVersion(key) != expected {
+			return errVersionMismatch
+		}
+		return nil
+	}
+}
+
+// deleteKeyIfVersion deletes the entry of the key only when its version is expected, and returns the deleted value.
+func (s *server) deleteKeyIfVersion(key string, expected uint64) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	defer s.lockEntry(key, "")()
+
+	val, err := s.haloDB.Get(s.kvKey(key))
+	if err != nil || s.entryVersion(key) != expected {
+		return "", errVersionMismatch
+	}
+	return val, s.deleteLocked(key, val)
+}
+
+func expectedVersionFromContext(ctx context.Context) (uint64, bool, error) {
+	v, ok := metadataValue(ctx, ExpectedVersionMetadataKey)
+	if !ok {
+		return 0, false, nil
+	}
+	ver, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, true, errors.Wrapf(err, "invalid expected version %s", v)
+	}
+	return ver, true, nil
+}
+
+// setVersionHeader returns the version of the entry in the response header.
+func setVersionHeader(ctx context.Context, v uint64) {
+	if v == 0 {
+		return
+	}
+	err := grpc.SetHeader(ctx, metadata.Pairs(VersionMetadataKey, strconv.FormatUint(v, 10)))
+	if err != nil {
+		log.Debugf("[Versioning]\tfailed to set the version header\t%+v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/vdaas/vald/apis/grpc/meta"
+	"github.com/vdaas/vald/apis/grpc/payload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// headerRecorder is a grpc.ServerTransportStream which records the response headers.
+type headerRecorder struct {
+	md metadata.MD
+}
+
+func (r *headerRecorder) Method() string {
+	return "/test"
+}
+
+func (r *headerRecorder) SetHeader(md metadata.MD) error {
+	r.md = metadata.Join(r.md, md)
+	return nil
+}
+
+func (r *headerRecorder) SendHeader(md metadata.MD) error {
+	return r.SetHeader(md)
+}
+
+func (r *headerRecorder) SetTrailer(md metadata.MD) error {
+	return nil
+}
+
+// vrOp is an operation of a versioning test, op is one of set, get and del.
+// expected is passed as the expected version when it is not empty.
+type vrOp struct {
+	op       string
+	key      string
+	val      string
+	expected string
+}
+
+// vrResult is the code of an operation, with the value read by get and the version of the response header.
+type vrResult struct {
+	code    codes.Code
+	val     string
+	version string
+}
+
+func runVersioningOps(s meta.MetaServer, ops []vrOp) []vrResult {
+	res := make([]vrResult, 0, len(ops))
+	for _, op := range ops {
+		hr := new(headerRecorder)
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), hr)
+		if len(op.expected) != 0 {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ExpectedVersionMetadataKey, op.expected))
+		}
+		var (
+			r   vrResult
+			err error
+		)
+		switch op.op {
+		case "set":
+			_, err = s.SetMeta(ctx, &payload.Meta_KeyVal{Key: op.key, Val: op.val})
+		case "get":
+			var v *payload.Meta_Val
+			v, err = s.GetMeta(ctx, &payload.Meta_Key{Key: op.key})
+			r.val = v.GetVal()
+		case "del":
+			_, err = s.DeleteMeta(ctx, &payload.Meta_Key{Key: op.key})
+		}
+		r.code = status.Code(err)
+		if vs := hr.md.Get(VersionMetadataKey); len(vs) != 0 {
+			r.version = vs[len(vs)-1]
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+func Test_server_versioning(t *testing.T) {
+	type args struct {
+		ops []vrOp
+	}
+	type fields struct {
+		versioning bool
+	}
+	type want struct {
+		results []vrResult
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, []vrResult) error
+	}
+	defaultCheckFunc := func(w want, res []vrResult) error {
+		if !reflect.DeepEqual(res, w.results) {
+			return errors.Errorf("got results = %v, want %v", res, w.results)
+		}
+		return nil
+	}
+	version := func(v int) string {
+		return strconv.Itoa(v)
+	}
+	tests := []test{
+		{
+			name: "every write increases the version returned in the header",
+			args: args{
+				ops: []vrOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y"},
+					{op: "get", key: "a"},
+					{op: "set", key: "b", val: "z"},
+				},
+			},
+			fields: fields{
+				versioning: true,
+			},
+			want: want{
+				results: []vrResult{
+					{code: codes.OK, version: version(1)},
+					{code: codes.OK, version: version(2)},
+					{code: codes.OK, val: "y", version: version(2)},
+					{code: codes.OK, version: version(1)},
+				},
+			},
+		},
+		{
+			name: "a write with another expected version fails and changes nothing",
+			args: args{
+				ops: []vrOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y", expected: version(1)},
+					{op: "set", key: "a", val: "z", expected: version(1)},
+					{op: "get", key: "a"},
+				},
+			},
+			fields: fields{
+				versioning: true,
+			},
+			want: want{
+				results: []vrResult{
+					{code: codes.OK, version: version(1)},
+					{code: codes.OK, version: version(2)},
+					{code: codes.FailedPrecondition},
+					{code: codes.OK, val: "y", version: version(2)},
+				},
+			},
+		},
+		{
+			name: "a delete with another expected version fails and the versions never go back",
+			args: args{
+				ops: []vrOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "del", key: "a", expected: version(2)},
+					{op: "del", key: "a", expected: version(1)},
+					{op: "get", key: "a"},
+					{op: "set", key: "a", val: "y"},
+				},
+			},
+			fields: fields{
+				versioning: true,
+			},
+			want: want{
+				results: []vrResult{
+					{code: codes.OK, version: version(1)},
+					{code: codes.FailedPrecondition},
+					{code: codes.OK},
+					{code: codes.NotFound},
+					{code: codes.OK, version: version(2)},
+				},
+			},
+		},
+		{
+			name: "an expected version of a missing key fails",
+			args: args{
+				ops: []vrOp{
+					{op: "set", key: "a", val: "x", expected: version(0)},
+					{op: "del", key: "a", expected: version(0)},
+				},
+			},
+			fields: fields{
+				versioning: true,
+			},
+			want: want{
+				results: []vrResult{
+					{code: codes.FailedPrecondition},
+					{code: codes.FailedPrecondition},
+				},
+			},
+		},
+		{
+			name: "an expected version is invalid without versioning",
+			args: args{
+				ops: []vrOp{
+					{op: "set", key: "a", val: "x"},
+					{op: "set", key: "a", val: "y", expected: version(1)},
+					{op: "get", key: "a"},
+				},
+			},
+			want: want{
+				results: []vrResult{
+					{code: codes.OK},
+					{code: codes.InvalidArgument},
+					{code: codes.OK, val: "x"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			s := New(WithHaloDB(newMemDB(nil)), WithVersioning(test.fields.versioning))
+
+			res := runVersioningOps(s, test.args.ops)
+			if err := checkFunc(test.want, res); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
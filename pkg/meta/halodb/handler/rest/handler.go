@@ -1,12 +1,35 @@
 package rest
 
 import (
+	"context"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/vdaas/vald/apis/grpc/meta"
 	"github.com/vdaas/vald/apis/grpc/payload"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
 	"github.com/rinx/vald-meta-halodb/internal/net/http/dump"
 	"github.com/rinx/vald-meta-halodb/internal/net/http/json"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var errNoExtension = errors.New("MetaExtension APIs are not served")
+
+// metadataHeaders are the HTTP headers passed to the meta APIs as gRPC metadata.
+var metadataHeaders = []string{
+	"Meta-TTL",
+	"Meta-Namespace",
+}
+
+// the metadata keys of the entry versions, they are the same as the ones of the gRPC handler.
+const (
+	versionMetadataKey         = "meta-version"
+	expectedVersionMetadataKey = "meta-expected-version"
 )
 
 type Handler interface {
@@ -21,10 +44,15 @@ type Handler interface {
 	DeleteMetas(w http.ResponseWriter, r *http.Request) (int, error)
 	DeleteMetaInverse(w http.ResponseWriter, r *http.Request) (int, error)
 	DeleteMetasInverse(w http.ResponseWriter, r *http.Request) (int, error)
+	GetMetaHistory(w http.ResponseWriter, r *http.Request) (int, error)
+	RollbackMeta(w http.ResponseWriter, r *http.Request) (int, error)
+	ListTrash(w http.ResponseWriter, r *http.Request) (int, error)
+	RestoreMeta(w http.ResponseWriter, r *http.Request) (int, error)
 }
 
 type handler struct {
 	meta meta.MetaServer
+	ext  extension.MetaExtensionServer
 }
 
 func New(opts ...Option) Handler {
@@ -36,6 +64,53 @@ func New(opts ...Option) Handler {
 	return h
 }
 
+// context returns the request context carrying metadataHeaders as incoming gRPC metadata.
+// If-Match is passed as the expected version, and the version in the response header is returned as ETag.
+func (h *handler) context(w http.ResponseWriter, r *http.Request) context.Context {
+	md := metadata.MD{}
+	for _, key := range metadataHeaders {
+		if v := r.Header.Get(key); len(v) != 0 {
+			md.Append(strings.ToLower(key), v)
+		}
+	}
+	if v := r.Header.Get("If-Match"); len(v) != 0 {
+		md.Append(expectedVersionMetadataKey, strings.Trim(strings.TrimPrefix(v, "W/"), `"`))
+	}
+	ctx := grpc.NewContextWithServerTransportStream(r.Context(), &headerStream{
+		w:      w,
+		method: r.URL.Path,
+	})
+	if len(md) == 0 {
+		return ctx
+	}
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+// headerStream passes the gRPC response headers set by the meta APIs to the HTTP response.
+type headerStream struct {
+	w      http.ResponseWriter
+	method string
+}
+
+func (s *headerStream) Method() string {
+	return s.method
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	if vs := md.Get(versionMetadataKey); len(vs) != 0 {
+		s.w.Header().Set("ETag", strconv.Quote(vs[len(vs)-1]))
+	}
+	return nil
+}
+
+func (s *headerStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *headerStream) SetTrailer(md metadata.MD) error {
+	return nil
+}
+
 func (h *handler) Index(w http.ResponseWriter, r *http.Request) (int, error) {
 	data := make(map[string]interface{})
 	return json.Handler(w, r, &data, func() (interface{}, error) {
@@ -46,69 +121,129 @@ func (h *handler) Index(w http.ResponseWriter, r *http.Request) (int, error) {
 func (h *handler) GetMeta(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_Key)
 	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.GetMeta(r.Context(), req)
+		return h.meta.GetMeta(h.context(w, r), req)
 	})
 }
 
 func (h *handler) GetMetas(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_Keys)
 	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.GetMetas(r.Context(), req)
+		return h.meta.GetMetas(h.context(w, r), req)
 	})
 }
 
 func (h *handler) GetMetaInverse(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_Val)
 	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.GetMetaInverse(r.Context(), req)
+		return h.meta.GetMetaInverse(h.context(w, r), req)
 	})
 }
 
 func (h *handler) GetMetasInverse(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_Vals)
 	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.GetMetasInverse(r.Context(), req)
+		return h.meta.GetMetasInverse(h.context(w, r), req)
 	})
 }
 
 func (h *handler) SetMeta(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_KeyVal)
-	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.SetMeta(r.Context(), req)
-	})
+	return versionStatusCode(r)(json.Handler(w, r, &req, func() (interface{}, error) {
+		return h.meta.SetMeta(h.context(w, r), req)
+	}))
 }
 
 func (h *handler) SetMetas(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_KeyVals)
-	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.SetMetas(r.Context(), req)
-	})
+	return statusCode(json.Handler(w, r, &req, func() (interface{}, error) {
+		return h.meta.SetMetas(h.context(w, r), req)
+	}))
 }
 
 func (h *handler) DeleteMeta(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_Key)
-	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.DeleteMeta(r.Context(), req)
-	})
+	return versionStatusCode(r)(json.Handler(w, r, &req, func() (interface{}, error) {
+		return h.meta.DeleteMeta(h.context(w, r), req)
+	}))
 }
 
 func (h *handler) DeleteMetas(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_Keys)
-	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.DeleteMetas(r.Context(), req)
-	})
+	return statusCode(json.Handler(w, r, &req, func() (interface{}, error) {
+		return h.meta.DeleteMetas(h.context(w, r), req)
+	}))
 }
 
 func (h *handler) DeleteMetaInverse(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_Val)
 	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.DeleteMetaInverse(r.Context(), req)
+		return h.meta.DeleteMetaInverse(h.context(w, r), req)
 	})
 }
 
 func (h *handler) DeleteMetasInverse(w http.ResponseWriter, r *http.Request) (int, error) {
 	req := new(payload.Meta_Vals)
 	return json.Handler(w, r, &req, func() (interface{}, error) {
-		return h.meta.DeleteMetasInverse(r.Context(), req)
+		return h.meta.DeleteMetasInverse(h.context(w, r), req)
 	})
 }
+
+func (h *handler) GetMetaHistory(w http.ResponseWriter, r *http.Request) (int, error) {
+	req := new(payload.Meta_Key)
+	return json.Handler(w, r, &req, func() (interface{}, error) {
+		if h.ext == nil {
+			return nil, errNoExtension
+		}
+		return h.ext.GetMetaHistory(h.context(w, r), req)
+	})
+}
+
+func (h *handler) RollbackMeta(w http.ResponseWriter, r *http.Request) (int, error) {
+	req := new(extension.RollbackRequest)
+	return statusCode(json.Handler(w, r, &req, func() (interface{}, error) {
+		if h.ext == nil {
+			return nil, errNoExtension
+		}
+		return h.ext.RollbackMeta(h.context(w, r), req)
+	}))
+}
+
+func (h *handler) ListTrash(w http.ResponseWriter, r *http.Request) (int, error) {
+	req := new(extension.TrashListRequest)
+	return json.Handler(w, r, &req, func() (interface{}, error) {
+		if h.ext == nil {
+			return nil, errNoExtension
+		}
+		return h.ext.ListTrash(h.context(w, r), req)
+	})
+}
+
+func (h *handler) RestoreMeta(w http.ResponseWriter, r *http.Request) (int, error) {
+	req := new(payload.Meta_Key)
+	return statusCode(json.Handler(w, r, &req, func() (interface{}, error) {
+		if h.ext == nil {
+			return nil, errNoExtension
+		}
+		return h.ext.RestoreMeta(h.context(w, r), req)
+	}))
+}
+
+// statusCode returns 429 when the namespace quota is exceeded.
+func statusCode(code int, err error) (int, error) {
+	if status.Code(err) == codes.ResourceExhausted {
+		return http.StatusTooManyRequests, err
+	}
+	return code, err
+}
+
+// versionStatusCode returns the statusCode of SetMeta and DeleteMeta, and 412 when the expected version of If-Match does not match.
+// They only fail with FAILED_PRECONDITION when the version does not match, which is only checked for the requests with If-Match.
+func versionStatusCode(r *http.Request) func(int, error) (int, error) {
+	ifMatch := len(r.Header.Get("If-Match")) != 0
+	return func(code int, err error) (int, error) {
+		if ifMatch && status.Code(err) == codes.FailedPrecondition {
+			return http.StatusPreconditionFailed, err
+		}
+		return statusCode(code, err)
+	}
+}
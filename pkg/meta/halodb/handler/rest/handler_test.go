@@ -0,0 +1,274 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func Test_statusCode(t *testing.T) {
+	type args struct {
+		code int
+		err  error
+	}
+	type want struct {
+		code int
+	}
+	type test struct {
+		name      string
+		args      args
+		want      want
+		checkFunc func(want, int, error) error
+	}
+	defaultCheckFunc := func(w want, code int, err error) error {
+		if code != w.code {
+			return errors.Errorf("got code = %d, want %d", code, w.code)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "a success keeps its code",
+			args: args{
+				code: http.StatusOK,
+			},
+			want: want{
+				code: http.StatusOK,
+			},
+		},
+		{
+			name: "a namespace over its quota is too many requests",
+			args: args{
+				code: http.StatusInternalServerError,
+				err:  status.Error(codes.ResourceExhausted, "namespace a exceeds its quota of 2 keys"),
+			},
+			want: want{
+				code: http.StatusTooManyRequests,
+			},
+		},
+		{
+			name: "a failed precondition keeps its code",
+			args: args{
+				code: http.StatusInternalServerError,
+				err:  status.Error(codes.FailedPrecondition, "trash is not enabled"),
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "other errors keep their code",
+			args: args{
+				code: http.StatusInternalServerError,
+				err:  status.Error(codes.PermissionDenied, "unknown namespace c"),
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+
+			code, err := statusCode(test.args.code, test.args.err)
+			if err := checkFunc(test.want, code, err); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_versionStatusCode(t *testing.T) {
+	type args struct {
+		ifMatch string
+		code    int
+		err     error
+	}
+	type want struct {
+		code int
+	}
+	type test struct {
+		name      string
+		args      args
+		want      want
+		checkFunc func(want, int, error) error
+	}
+	defaultCheckFunc := func(w want, code int, err error) error {
+		if code != w.code {
+			return errors.Errorf("got code = %d, want %d", code, w.code)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "a failed precondition with If-Match is a version mismatch",
+			args: args{
+				ifMatch: `"1"`,
+				code:    http.StatusInternalServerError,
+				err:     status.Error(codes.FailedPrecondition, "SetMeta API haloDB key a version is not 1"),
+			},
+			want: want{
+				code: http.StatusPreconditionFailed,
+			},
+		},
+		{
+			name: "a failed precondition without If-Match keeps its code",
+			args: args{
+				code: http.StatusInternalServerError,
+				err:  status.Error(codes.FailedPrecondition, "Transaction API haloDB transactions are not enabled"),
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "a success with If-Match keeps its code",
+			args: args{
+				ifMatch: `"1"`,
+				code:    http.StatusOK,
+			},
+			want: want{
+				code: http.StatusOK,
+			},
+		},
+		{
+			name: "a namespace over its quota with If-Match is too many requests",
+			args: args{
+				ifMatch: `"1"`,
+				code:    http.StatusInternalServerError,
+				err:     status.Error(codes.ResourceExhausted, "namespace a exceeds its quota of 2 keys"),
+			},
+			want: want{
+				code: http.StatusTooManyRequests,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			r := httptest.NewRequest(http.MethodPost, "/meta", nil)
+			if len(test.args.ifMatch) != 0 {
+				r.Header.Set("If-Match", test.args.ifMatch)
+			}
+
+			code, err := versionStatusCode(r)(test.args.code, test.args.err)
+			if err := checkFunc(test.want, code, err); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_handler_context(t *testing.T) {
+	type args struct {
+		header map[string]string
+		// version is set to the gRPC response header, it is not set when it is empty
+		version string
+	}
+	type want struct {
+		md   metadata.MD
+		etag string
+	}
+	type test struct {
+		name      string
+		args      args
+		want      want
+		checkFunc func(want, metadata.MD, string) error
+	}
+	defaultCheckFunc := func(w want, md metadata.MD, etag string) error {
+		if !reflect.DeepEqual(md, w.md) {
+			return errors.Errorf("got metadata = %v, want %v", md, w.md)
+		}
+		if etag != w.etag {
+			return errors.Errorf("got ETag = %v, want %v", etag, w.etag)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "If-Match is passed as the expected version",
+			args: args{
+				header: map[string]string{
+					"If-Match": `"3"`,
+				},
+			},
+			want: want{
+				md: metadata.Pairs(expectedVersionMetadataKey, "3"),
+			},
+		},
+		{
+			name: "a weak If-Match is passed as the expected version",
+			args: args{
+				header: map[string]string{
+					"If-Match": `W/"3"`,
+				},
+			},
+			want: want{
+				md: metadata.Pairs(expectedVersionMetadataKey, "3"),
+			},
+		},
+		{
+			name: "the metadata headers are passed with If-Match",
+			args: args{
+				header: map[string]string{
+					"If-Match":       `"3"`,
+					"Meta-Namespace": "a",
+				},
+			},
+			want: want{
+				md: metadata.Pairs("meta-namespace", "a", expectedVersionMetadataKey, "3"),
+			},
+		},
+		{
+			name: "the version of the response header is returned as ETag",
+			args: args{
+				version: "4",
+			},
+			want: want{
+				etag: `"4"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			h := New().(*handler)
+			r := httptest.NewRequest(http.MethodPost, "/meta", nil)
+			for k, v := range test.args.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+
+			ctx := h.context(w, r)
+			md, _ := metadata.FromIncomingContext(ctx)
+			if len(test.args.version) != 0 {
+				if err := grpc.SetHeader(ctx, metadata.Pairs(versionMetadataKey, test.args.version)); err != nil {
+					tt.Fatal(err)
+				}
+			}
+			if err := checkFunc(test.want, md, w.Header().Get("ETag")); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package rest
 
-import "github.com/vdaas/vald/apis/grpc/meta"
+import (
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
+	"github.com/vdaas/vald/apis/grpc/meta"
+)
 
 type Option func(*handler)
 
@@ -13,3 +16,10 @@ func WithMeta(m meta.MetaServer) Option {
 		h.meta = m
 	}
 }
+
+// WithExtension sets the MetaExtension server, its REST APIs fail without it.
+func WithExtension(e extension.MetaExtensionServer) Option {
+	return func(h *handler) {
+		h.ext = e
+	}
+}
@@ -0,0 +1,69 @@
+// Package cache provides functions for read cache stats
+package cache
+
+import (
+	"context"
+
+	"github.com/rinx/vald-meta-halodb/internal/observability/metrics"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+)
+
+type cacheMetrics struct {
+	cache      service.Cache
+	hitTotal   metrics.Int64Measure
+	missTotal  metrics.Int64Measure
+	entryCount metrics.Int64Measure
+}
+
+func New(c service.Cache) metrics.Metric {
+	return &cacheMetrics{
+		cache: c,
+		hitTotal: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/cache_hit_total",
+			"the cumulative count of cache hits",
+			metrics.UnitDimensionless),
+		missTotal: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/cache_miss_total",
+			"the cumulative count of cache misses",
+			metrics.UnitDimensionless),
+		entryCount: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/cache_entry_count",
+			"cached entry count",
+			metrics.UnitDimensionless),
+	}
+}
+
+func (c *cacheMetrics) Measurement(ctx context.Context) ([]metrics.Measurement, error) {
+	return []metrics.Measurement{
+		c.hitTotal.M(int64(c.cache.Hits())),
+		c.missTotal.M(int64(c.cache.Misses())),
+		c.entryCount.M(int64(c.cache.Len())),
+	}, nil
+}
+
+func (c *cacheMetrics) MeasurementWithTags(ctx context.Context) ([]metrics.MeasurementWithTags, error) {
+	return []metrics.MeasurementWithTags{}, nil
+}
+
+func (c *cacheMetrics) View() []*metrics.View {
+	return []*metrics.View{
+		&metrics.View{
+			Name:        "meta_halodb_cache_hit_total",
+			Description: "the cumulative count of cache hits",
+			Measure:     &c.hitTotal,
+			Aggregation: metrics.LastValue(),
+		},
+		&metrics.View{
+			Name:        "meta_halodb_cache_miss_total",
+			Description: "the cumulative count of cache misses",
+			Measure:     &c.missTotal,
+			Aggregation: metrics.LastValue(),
+		},
+		&metrics.View{
+			Name:        "meta_halodb_cache_entry_count",
+			Description: "cached entry count",
+			Measure:     &c.entryCount,
+			Aggregation: metrics.LastValue(),
+		},
+	}
+}
@@ -0,0 +1,45 @@
+// Package coalesce provides functions for read coalescing stats
+package coalesce
+
+import (
+	"context"
+
+	"github.com/rinx/vald-meta-halodb/internal/observability/metrics"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+)
+
+type coalesceMetrics struct {
+	coalescer      service.Coalescer
+	coalescedTotal metrics.Int64Measure
+}
+
+func New(c service.Coalescer) metrics.Metric {
+	return &coalesceMetrics{
+		coalescer: c,
+		coalescedTotal: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/coalesced_total",
+			"the cumulative count of reads served by another in-flight read",
+			metrics.UnitDimensionless),
+	}
+}
+
+func (c *coalesceMetrics) Measurement(ctx context.Context) ([]metrics.Measurement, error) {
+	return []metrics.Measurement{
+		c.coalescedTotal.M(int64(c.coalescer.Coalesced())),
+	}, nil
+}
+
+func (c *coalesceMetrics) MeasurementWithTags(ctx context.Context) ([]metrics.MeasurementWithTags, error) {
+	return []metrics.MeasurementWithTags{}, nil
+}
+
+func (c *coalesceMetrics) View() []*metrics.View {
+	return []*metrics.View{
+		&metrics.View{
+			Name:        "meta_halodb_coalesced_total",
+			Description: "the cumulative count of reads served by another in-flight read",
+			Measure:     &c.coalescedTotal,
+			Aggregation: metrics.LastValue(),
+		},
+	}
+}
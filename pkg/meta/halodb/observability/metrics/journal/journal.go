@@ -0,0 +1,69 @@
+// Package journal provides functions for async write stats
+package journal
+
+import (
+	"context"
+
+	"github.com/rinx/vald-meta-halodb/internal/observability/metrics"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+)
+
+type journalMetrics struct {
+	journal    service.Journal
+	queueDepth metrics.Int64Measure
+	applyLag   metrics.Int64Measure
+	failed     metrics.Int64Measure
+}
+
+func New(j service.Journal) metrics.Metric {
+	return &journalMetrics{
+		journal: j,
+		queueDepth: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/journal_queue_depth",
+			"the number of journaled mutations waiting to be applied",
+			metrics.UnitDimensionless),
+		applyLag: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/journal_apply_lag",
+			"how long the last applied mutation waited in the queue",
+			metrics.UnitMilliseconds),
+		failed: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/journal_failed_mutations",
+			"the number of journaled mutations dropped because they could not be applied",
+			metrics.UnitDimensionless),
+	}
+}
+
+func (j *journalMetrics) Measurement(ctx context.Context) ([]metrics.Measurement, error) {
+	return []metrics.Measurement{
+		j.queueDepth.M(int64(j.journal.Pending())),
+		j.applyLag.M(j.journal.Lag().Milliseconds()),
+		j.failed.M(j.journal.Failed()),
+	}, nil
+}
+
+func (j *journalMetrics) MeasurementWithTags(ctx context.Context) ([]metrics.MeasurementWithTags, error) {
+	return []metrics.MeasurementWithTags{}, nil
+}
+
+func (j *journalMetrics) View() []*metrics.View {
+	return []*metrics.View{
+		&metrics.View{
+			Name:        "meta_halodb_journal_queue_depth",
+			Description: "the number of journaled mutations waiting to be applied",
+			Measure:     &j.queueDepth,
+			Aggregation: metrics.LastValue(),
+		},
+		&metrics.View{
+			Name:        "meta_halodb_journal_apply_lag",
+			Description: "how long the last applied mutation waited in the queue",
+			Measure:     &j.applyLag,
+			Aggregation: metrics.LastValue(),
+		},
+		&metrics.View{
+			Name:        "meta_halodb_journal_failed_mutations",
+			Description: "the number of journaled mutations dropped because they could not be applied",
+			Measure:     &j.failed,
+			Aggregation: metrics.LastValue(),
+		},
+	}
+}
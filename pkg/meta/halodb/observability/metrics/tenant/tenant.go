@@ -0,0 +1,85 @@
+// Package tenant provides functions for per tenant HaloDB stats
+package tenant
+
+import (
+	"context"
+
+	"github.com/rinx/vald-meta-halodb/internal/observability/metrics"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+)
+
+type tenantMetrics struct {
+	registry service.Registry
+	key      metrics.Key
+	opened   metrics.Int64Measure
+	size     metrics.Int64Measure
+}
+
+func New(r service.Registry) (metrics.Metric, error) {
+	key, err := metrics.NewKey("tenant")
+	if err != nil {
+		return nil, err
+	}
+	return &tenantMetrics{
+		registry: r,
+		key:      key,
+		opened: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/tenant_opened",
+			"whether the HaloDB of the tenant is opened",
+			metrics.UnitDimensionless),
+		size: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/tenant_size",
+			"the number of HaloDB entries of the tenant",
+			metrics.UnitDimensionless),
+	}, nil
+}
+
+func (t *tenantMetrics) Measurement(ctx context.Context) ([]metrics.Measurement, error) {
+	return []metrics.Measurement{}, nil
+}
+
+func (t *tenantMetrics) MeasurementWithTags(ctx context.Context) ([]metrics.MeasurementWithTags, error) {
+	ts := t.registry.Tenants()
+	mwts := make([]metrics.MeasurementWithTags, 0, len(ts)*2)
+	for _, tn := range ts {
+		tags := map[metrics.Key]string{
+			t.key: tn.Name(),
+		}
+		var opened int64
+		if tn.Opened() {
+			opened = 1
+		}
+		mwts = append(mwts, metrics.MeasurementWithTags{
+			Measurement: t.opened.M(opened),
+			Tags:        tags,
+		})
+		size, err := tn.Size()
+		if err != nil {
+			return nil, err
+		}
+		mwts = append(mwts, metrics.MeasurementWithTags{
+			Measurement: t.size.M(size),
+			Tags:        tags,
+		})
+	}
+	return mwts, nil
+}
+
+func (t *tenantMetrics) View() []*metrics.View {
+	return []*metrics.View{
+		&metrics.View{
+			Name:        "meta_halodb_tenant_opened",
+			Description: "whether the HaloDB of the tenant is opened",
+			TagKeys:     []metrics.Key{t.key},
+			Measure:     &t.opened,
+			Aggregation: metrics.LastValue(),
+		},
+		&metrics.View{
+			Name:        "meta_halodb_tenant_size",
+			Description: "the number of HaloDB entries of the tenant",
+			TagKeys:     []metrics.Key{t.key},
+			Measure:     &t.size,
+			Aggregation: metrics.LastValue(),
+		},
+	}
+}
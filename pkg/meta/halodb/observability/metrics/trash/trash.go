@@ -0,0 +1,45 @@
+// Package trash provides functions for soft deleted entry stats
+package trash
+
+import (
+	"context"
+
+	"github.com/rinx/vald-meta-halodb/internal/observability/metrics"
+	handler "github.com/rinx/vald-meta-halodb/pkg/meta/halodb/handler/grpc"
+)
+
+type trashMetrics struct {
+	sweeper     handler.TrashSweeper
+	purgedTotal metrics.Int64Measure
+}
+
+func New(s handler.TrashSweeper) metrics.Metric {
+	return &trashMetrics{
+		sweeper: s,
+		purgedTotal: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/trash_purged_total",
+			"the cumulative count of purged trash entries",
+			metrics.UnitDimensionless),
+	}
+}
+
+func (t *trashMetrics) Measurement(ctx context.Context) ([]metrics.Measurement, error) {
+	return []metrics.Measurement{
+		t.purgedTotal.M(int64(t.sweeper.Purged())),
+	}, nil
+}
+
+func (t *trashMetrics) MeasurementWithTags(ctx context.Context) ([]metrics.MeasurementWithTags, error) {
+	return []metrics.MeasurementWithTags{}, nil
+}
+
+func (t *trashMetrics) View() []*metrics.View {
+	return []*metrics.View{
+		&metrics.View{
+			Name:        "meta_halodb_trash_purged_total",
+			Description: "the cumulative count of purged trash entries",
+			Measure:     &t.purgedTotal,
+			Aggregation: metrics.LastValue(),
+		},
+	}
+}
@@ -0,0 +1,45 @@
+// Package ttl provides functions for entry expiration stats
+package ttl
+
+import (
+	"context"
+
+	"github.com/rinx/vald-meta-halodb/internal/observability/metrics"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
+)
+
+type ttlMetrics struct {
+	ttl          service.TTL
+	expiredTotal metrics.Int64Measure
+}
+
+func New(t service.TTL) metrics.Metric {
+	return &ttlMetrics{
+		ttl: t,
+		expiredTotal: *metrics.Int64(
+			metrics.ValdOrg+"/meta/halodb/expired_total",
+			"the cumulative count of expired entries",
+			metrics.UnitDimensionless),
+	}
+}
+
+func (t *ttlMetrics) Measurement(ctx context.Context) ([]metrics.Measurement, error) {
+	return []metrics.Measurement{
+		t.expiredTotal.M(int64(t.ttl.Expired())),
+	}, nil
+}
+
+func (t *ttlMetrics) MeasurementWithTags(ctx context.Context) ([]metrics.MeasurementWithTags, error) {
+	return []metrics.MeasurementWithTags{}, nil
+}
+
+func (t *ttlMetrics) View() []*metrics.View {
+	return []*metrics.View{
+		&metrics.View{
+			Name:        "meta_halodb_expired_total",
+			Description: "the cumulative count of expired entries",
+			Measure:     &t.expiredTotal,
+			Aggregation: metrics.LastValue(),
+		},
+	}
+}
@@ -121,5 +121,37 @@ func New(opts ...Option) http.Handler {
 				"/inverse/metas",
 				h.DeleteMetasInverse,
 			},
+			{
+				"GetMetaHistory",
+				[]string{
+					http.MethodGet,
+				},
+				"/history/meta",
+				h.GetMetaHistory,
+			},
+			{
+				"RollbackMeta",
+				[]string{
+					http.MethodPost,
+				},
+				"/rollback/meta",
+				h.RollbackMeta,
+			},
+			{
+				"ListTrash",
+				[]string{
+					http.MethodGet,
+				},
+				"/trash/meta",
+				h.ListTrash,
+			},
+			{
+				"RestoreMeta",
+				[]string{
+					http.MethodPost,
+				},
+				"/restore/meta",
+				h.RestoreMeta,
+			},
 		}...))
 }
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type cacheEntry struct {
+	key    string
+	val    string
+	found  bool
+	expire int64
+}
+
+type cache struct {
+	HaloDB
+	// locks serialize the writes of each key so the cache is updated in the same order as HaloDB
+	locks  [generationStripes]sync.Mutex
+	mu     sync.Mutex
+	lru    *list.List
+	items  map[string]*list.Element
+	size   int
+	expire time.Duration
+	seq    uint64
+	hits   uint64
+	misses uint64
+}
+
+// Cache is a HaloDB with a bounded read-through LRU cache.
+// Put and Delete update the cache after HaloDB, deleted keys are remembered
+// until they are evicted so a concurrent read cannot restore them.
+// Keys not found in HaloDB are cached as misses too, so repeated reads of missing keys skip the native call.
+type Cache interface {
+	HaloDB
+	Hits() uint64
+	Misses() uint64
+	Len() int
+}
+
+func NewCache(h HaloDB, opts ...CacheOption) (Cache, error) {
+	c := &cache{
+		HaloDB: h,
+		lru:    list.New(),
+		items:  make(map[string]*list.Element),
+	}
+
+	for _, opt := range append(defaultCacheOpts, opts...) {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *cache) load(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elm, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := elm.Value.(*cacheEntry)
+	if e.expire > 0 && e.expire < time.Now().UnixNano() {
+		c.remove(elm)
+		return nil, false
+	}
+	c.lru.MoveToFront(elm)
+	return e, true
+}
+
+// store must be called with c.mu held.
+func (c *cache) store(key, val string, found bool) {
+	var expire int64
+	if c.expire > 0 {
+		expire = time.Now().Add(c.expire).UnixNano()
+	}
+	e := &cacheEntry{
+		key:    key,
+		val:    val,
+		found:  found,
+		expire: expire,
+	}
+	if elm, ok := c.items[key]; ok {
+		elm.Value = e
+		c.lru.MoveToFront(elm)
+		return
+	}
+	c.items[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *cache) remove(elm *list.Element) {
+	c.lru.Remove(elm)
+	delete(c.items, elm.Value.(*cacheEntry).key)
+}
+
+func (c *cache) Get(key string) (string, error) {
+	if e, ok := c.load(key); ok {
+		atomic.AddUint64(&c.hits, 1)
+		if !e.found {
+			return "", errNotFound(key)
+		}
+		return e.val, nil
+	}
+	atomic.AddUint64(&c.misses, 1)
+
+	seq := atomic.LoadUint64(&c.seq)
+	val, err := c.HaloDB.Get(key)
+	found := err == nil
+	if err != nil && !isNotFound(err) {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the value may be stale when any write happened during the read
+	if _, ok := c.items[key]; !ok && atomic.LoadUint64(&c.seq) == seq {
+		c.store(key, val, found)
+	}
+	return val, err
+}
+
+func (c *cache) lock(key string) *sync.Mutex {
+	return &c.locks[hash(key)%generationStripes]
+}
+
+func (c *cache) Put(key, value string) error {
+	l := c.lock(key)
+	l.Lock()
+	defer l.Unlock()
+
+	err := c.HaloDB.Put(key, value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	atomic.AddUint64(&c.seq, 1)
+	if err != nil {
+		if elm, ok := c.items[key]; ok {
+			c.remove(elm)
+		}
+		return err
+	}
+	c.store(key, value, true)
+	return nil
+}
+
+func (c *cache) Delete(key string) error {
+	l := c.lock(key)
+	l.Lock()
+	defer l.Unlock()
+
+	err := c.HaloDB.Delete(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	atomic.AddUint64(&c.seq, 1)
+	if err != nil {
+		if elm, ok := c.items[key]; ok {
+			c.remove(elm)
+		}
+		return err
+	}
+	c.store(key, "", false)
+	return nil
+}
+
+func (c *cache) Hits() uint64 {
+	return atomic.LoadUint64(&c.hits)
+}
+
+func (c *cache) Misses() uint64 {
+	return atomic.LoadUint64(&c.misses)
+}
+
+func (c *cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Commit holds the locks of all the keys, so the cache is updated in the same order as HaloDB.
+func (c *cache) Commit(ms []Mutation) error {
+	var held [generationStripes]bool
+	for _, m := range ms {
+		held[hash(m.Key)%generationStripes] = true
+	}
+	// the stripes are locked in order so two commits cannot deadlock
+	for i := range held {
+		if held[i] {
+			c.locks[i].Lock()
+			defer c.locks[i].Unlock()
+		}
+	}
+
+	err := Commit(c.HaloDB, ms)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	atomic.AddUint64(&c.seq, 1)
+	for _, m := range ms {
+		if err != nil {
+			if elm, ok := c.items[m.Key]; ok {
+				c.remove(elm)
+			}
+			continue
+		}
+		c.store(m.Key, m.Value, !m.Delete)
+	}
+	return err
+}
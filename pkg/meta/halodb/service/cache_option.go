@@ -0,0 +1,38 @@
+package service
+
+import (
+	"github.com/rinx/vald-meta-halodb/internal/timeutil"
+)
+
+type CacheOption func(*cache) error
+
+var (
+	defaultCacheOpts = []CacheOption{
+		WithCacheSize(100000),
+		WithCacheExpireDuration("30m"),
+	}
+)
+
+func WithCacheSize(size int) CacheOption {
+	return func(c *cache) error {
+		if size <= 0 {
+			return nil
+		}
+		c.size = size
+		return nil
+	}
+}
+
+func WithCacheExpireDuration(dur string) CacheOption {
+	return func(c *cache) error {
+		if len(dur) == 0 {
+			return nil
+		}
+		d, err := timeutil.Parse(dur)
+		if err != nil {
+			return err
+		}
+		c.expire = d
+		return nil
+	}
+}
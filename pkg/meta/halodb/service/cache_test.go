@@ -0,0 +1,322 @@
+package service
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+func Test_cache_Get(t *testing.T) {
+	type op struct {
+		put string
+		del string
+		get string
+	}
+	type args struct {
+		ops []op
+	}
+	type fields struct {
+		kvs  map[string]string
+		size int
+	}
+	type want struct {
+		val  string
+		err  error
+		gets uint64
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, string, error, uint64) error
+	}
+	defaultCheckFunc := func(w want, val string, err error, gets uint64) error {
+		if (err == nil) != (w.err == nil) || (err != nil && err.Error() != w.err.Error()) {
+			return errors.Errorf("got error = %v, want %v", err, w.err)
+		}
+		if val != w.val {
+			return errors.Errorf("got value = %v, want %v", val, w.val)
+		}
+		if gets != w.gets {
+			return errors.Errorf("got reads of HaloDB = %v, want %v", gets, w.gets)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "a cached value is served without reading HaloDB",
+			args: args{
+				ops: []op{
+					{get: "a"},
+					{get: "a"},
+				},
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"a": "1",
+				},
+			},
+			want: want{
+				val:  "1",
+				gets: 1,
+			},
+		},
+		{
+			name: "a missing key is cached as a miss",
+			args: args{
+				ops: []op{
+					{get: "a"},
+					{get: "a"},
+				},
+			},
+			want: want{
+				err:  errNotFound("a"),
+				gets: 1,
+			},
+		},
+		{
+			name: "a deleted key is cached as a miss",
+			args: args{
+				ops: []op{
+					{get: "a"},
+					{del: "a"},
+					{get: "a"},
+				},
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"a": "1",
+				},
+			},
+			want: want{
+				err:  errNotFound("a"),
+				gets: 1,
+			},
+		},
+		{
+			name: "a put value is served without reading HaloDB",
+			args: args{
+				ops: []op{
+					{put: "a"},
+					{get: "a"},
+				},
+			},
+			want: want{
+				val: "a",
+			},
+		},
+		{
+			name: "the least recently used key is evicted",
+			args: args{
+				ops: []op{
+					{get: "a"},
+					{get: "b"},
+					{get: "c"},
+					{get: "a"},
+				},
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"a": "1",
+					"b": "2",
+					"c": "3",
+				},
+				size: 2,
+			},
+			want: want{
+				val:  "1",
+				gets: 4,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := &slowDB{
+				memDB: newMemDB(test.fields.kvs),
+			}
+			c, err := NewCache(db, WithCacheSize(test.fields.size))
+			if err != nil {
+				tt.Fatal(err)
+			}
+
+			var (
+				val  string
+				gerr error
+			)
+			for _, op := range test.args.ops {
+				switch {
+				case len(op.put) != 0:
+					err = c.Put(op.put, op.put)
+				case len(op.del) != 0:
+					err = c.Delete(op.del)
+				default:
+					val, gerr = c.Get(op.get)
+					continue
+				}
+				if err != nil {
+					tt.Fatal(err)
+				}
+			}
+			if err := checkFunc(test.want, val, gerr, atomic.LoadUint64(&db.gets)); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_cache_concurrent(t *testing.T) {
+	type args struct {
+		keys    int
+		writes  int
+		readers int
+	}
+	type fields struct {
+		size int
+	}
+	type test struct {
+		name   string
+		args   args
+		fields fields
+	}
+	tests := []test{
+		{
+			name: "the reads see the finished writes",
+			args: args{
+				keys:    8,
+				writes:  200,
+				readers: 4,
+			},
+			fields: fields{
+				size: 1024,
+			},
+		},
+		{
+			name: "the reads see the finished writes while the keys are evicted",
+			args: args{
+				keys:    8,
+				writes:  200,
+				readers: 4,
+			},
+			fields: fields{
+				size: 2,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			c, err := NewCache(newMemDB(nil), WithCacheSize(test.fields.size))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			keys := make([]string, 0, test.args.keys)
+			for i := 0; i < test.args.keys; i++ {
+				keys = append(keys, "k"+strconv.Itoa(i))
+			}
+			if err := checkReadAfterWrite(c, keys, test.args.writes, test.args.readers); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_cache_consistency(t *testing.T) {
+	type args struct {
+		keys    int
+		writers int
+		writes  int
+		readers int
+	}
+	type test struct {
+		name string
+		args args
+	}
+	tests := []test{
+		{
+			name: "the cache agrees with HaloDB after concurrent puts and deletes",
+			args: args{
+				keys:    4,
+				writers: 4,
+				writes:  200,
+				readers: 4,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			db := newMemDB(nil)
+			c, err := NewCache(db)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			keys := make([]string, 0, test.args.keys)
+			for i := 0; i < test.args.keys; i++ {
+				keys = append(keys, "k"+strconv.Itoa(i))
+			}
+
+			var wg sync.WaitGroup
+			done := make(chan struct{})
+			for w := 0; w < test.args.writers; w++ {
+				w := w
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for n := 0; n < test.args.writes; n++ {
+						key := keys[(w+n)%len(keys)]
+						if (w+n)%3 == 0 {
+							// deleting a missing key fails in HaloDB and is expected here
+							_ = c.Delete(key)
+							continue
+						}
+						if err := c.Put(key, strconv.Itoa(w)+"-"+strconv.Itoa(n)); err != nil {
+							tt.Error(err)
+							return
+						}
+					}
+				}()
+			}
+			var rwg sync.WaitGroup
+			for r := 0; r < test.args.readers; r++ {
+				rwg.Add(1)
+				go func() {
+					defer rwg.Done()
+					for {
+						select {
+						case <-done:
+							return
+						default:
+						}
+						for _, key := range keys {
+							if _, err := c.Get(key); err != nil && !isNotFound(err) {
+								tt.Error(err)
+								return
+							}
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			close(done)
+			rwg.Wait()
+
+			kvs := db.entries()
+			for _, key := range keys {
+				val, err := c.Get(key)
+				want, found := kvs[key]
+				if found != (err == nil) || val != want {
+					tt.Errorf("got %s = %v (%v), want %v (found %v)", key, val, err, want, found)
+				}
+			}
+		})
+	}
+}
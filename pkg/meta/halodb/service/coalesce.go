@@ -0,0 +1,89 @@
+package service
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+const generationStripes = 256
+
+// flight is a native Get shared by the concurrent reads of a key.
+type flight struct {
+	wg  sync.WaitGroup
+	val string
+	err error
+}
+
+type coalescer struct {
+	HaloDB
+	mu        sync.Mutex
+	flights   map[string]*flight
+	gens      [generationStripes]uint64
+	coalesced uint64
+}
+
+// Coalescer is a HaloDB which shares one native Get between the concurrent reads of the same key.
+// A read never joins a call which started before the last write to its key finished.
+type Coalescer interface {
+	HaloDB
+	Coalesced() uint64
+}
+
+func NewCoalescer(h HaloDB) Coalescer {
+	return &coalescer{
+		HaloDB:  h,
+		flights: make(map[string]*flight),
+	}
+}
+
+func (c *coalescer) stripe(key string) *uint64 {
+	return &c.gens[hash(key)%generationStripes]
+}
+
+func (c *coalescer) Get(key string) (string, error) {
+	id := strconv.FormatUint(atomic.LoadUint64(c.stripe(key)), 10) + ":" + key
+
+	c.mu.Lock()
+	if f, ok := c.flights[id]; ok {
+		c.mu.Unlock()
+		atomic.AddUint64(&c.coalesced, 1)
+		f.wg.Wait()
+		return f.val, f.err
+	}
+	f := new(flight)
+	f.wg.Add(1)
+	c.flights[id] = f
+	c.mu.Unlock()
+
+	f.val, f.err = c.HaloDB.Get(key)
+	f.wg.Done()
+
+	c.mu.Lock()
+	delete(c.flights, id)
+	c.mu.Unlock()
+	return f.val, f.err
+}
+
+func (c *coalescer) Put(key, value string) error {
+	defer atomic.AddUint64(c.stripe(key), 1)
+	return c.HaloDB.Put(key, value)
+}
+
+func (c *coalescer) Delete(key string) error {
+	defer atomic.AddUint64(c.stripe(key), 1)
+	return c.HaloDB.Delete(key)
+}
+
+func (c *coalescer) Coalesced() uint64 {
+	return atomic.LoadUint64(&c.coalesced)
+}
+
+func (c *coalescer) Commit(ms []Mutation) error {
+	defer func() {
+		for _, m := range ms {
+			atomic.AddUint64(c.stripe(m.Key), 1)
+		}
+	}()
+	return Commit(c.HaloDB, ms)
+}
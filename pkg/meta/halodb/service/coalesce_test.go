@@ -0,0 +1,161 @@
+package service
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+func Test_coalescer_Get(t *testing.T) {
+	type args struct {
+		key     string
+		readers int
+	}
+	type fields struct {
+		kvs   map[string]string
+		delay time.Duration
+	}
+	type want struct {
+		val string
+		err error
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, []string, []error, uint64, Coalescer) error
+	}
+	defaultCheckFunc := func(w want, vals []string, errs []error, gets uint64, c Coalescer) error {
+		for i := range vals {
+			if (errs[i] == nil) != (w.err == nil) || (errs[i] != nil && errs[i].Error() != w.err.Error()) {
+				return errors.Errorf("got error = %v, want %v", errs[i], w.err)
+			}
+			if vals[i] != w.val {
+				return errors.Errorf("got value = %v, want %v", vals[i], w.val)
+			}
+		}
+		if c.Coalesced() == 0 || gets+c.Coalesced() != uint64(len(vals)) {
+			return errors.Errorf("got reads of HaloDB = %v and coalesced reads = %v for %d reads", gets, c.Coalesced(), len(vals))
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the concurrent reads of a key share the reads of HaloDB",
+			args: args{
+				key:     "a",
+				readers: 16,
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"a": "1",
+				},
+				delay: 50 * time.Millisecond,
+			},
+			want: want{
+				val: "1",
+			},
+		},
+		{
+			name: "the concurrent reads of a missing key share the error",
+			args: args{
+				key:     "a",
+				readers: 16,
+			},
+			fields: fields{
+				delay: 50 * time.Millisecond,
+			},
+			want: want{
+				err: errNotFound("a"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := &slowDB{
+				memDB: newMemDB(test.fields.kvs),
+				delay: test.fields.delay,
+			}
+			c := NewCoalescer(db)
+
+			vals := make([]string, test.args.readers)
+			errs := make([]error, test.args.readers)
+			var wg sync.WaitGroup
+			for i := 0; i < test.args.readers; i++ {
+				i := i
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					vals[i], errs[i] = c.Get(test.args.key)
+				}()
+			}
+			wg.Wait()
+			if err := checkFunc(test.want, vals, errs, atomic.LoadUint64(&db.gets), c); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_coalescer_concurrent(t *testing.T) {
+	type args struct {
+		keys    int
+		writes  int
+		readers int
+	}
+	type fields struct {
+		delay time.Duration
+	}
+	type test struct {
+		name   string
+		args   args
+		fields fields
+	}
+	tests := []test{
+		{
+			name: "the reads see the finished writes",
+			args: args{
+				keys:    4,
+				writes:  200,
+				readers: 8,
+			},
+		},
+		{
+			name: "the reads see the finished writes while the reads of HaloDB are slow",
+			args: args{
+				keys:    4,
+				writes:  50,
+				readers: 8,
+			},
+			fields: fields{
+				delay: 100 * time.Microsecond,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			c := NewCoalescer(&slowDB{
+				memDB: newMemDB(nil),
+				delay: test.fields.delay,
+			})
+			keys := make([]string, 0, test.args.keys)
+			for i := 0; i < test.args.keys; i++ {
+				keys = append(keys, "k"+strconv.Itoa(i))
+			}
+			if err := checkReadAfterWrite(c, keys, test.args.writes, test.args.readers); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/safety"
+)
+
+var errGroupCommitStopped = errors.New("group commit is stopped")
+
+type pendingMutation struct {
+	Mutation
+	ech chan error
+}
+
+type committer struct {
+	HaloDB
+	batcher Batcher
+	ch      chan *pendingMutation
+	done    chan struct{}
+	window  time.Duration
+	size    int
+	eg      errgroup.Group
+}
+
+// Committer is a HaloDB which groups the concurrent Put and Delete calls
+// arriving within the window, or until the batch size is reached, into one Write.
+type Committer interface {
+	HaloDB
+	Start(ctx context.Context) <-chan error
+}
+
+func NewCommitter(h HaloDB, opts ...CommitterOption) (Committer, error) {
+	b, ok := h.(Batcher)
+	if !ok {
+		return nil, errors.New("group commit requires a batch writer")
+	}
+	c := &committer{
+		HaloDB:  h,
+		batcher: b,
+		done:    make(chan struct{}),
+	}
+
+	for _, opt := range append(defaultCommitterOpts, opts...) {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	c.ch = make(chan *pendingMutation, c.size)
+
+	return c, nil
+}
+
+func (c *committer) apply(m Mutation) error {
+	pm := &pendingMutation{
+		Mutation: m,
+		ech:      make(chan error, 1),
+	}
+	select {
+	case <-c.done:
+		return errGroupCommitStopped
+	case c.ch <- pm:
+	}
+	select {
+	case err := <-pm.ech:
+		return err
+	case <-c.done:
+		// the mutation may have been queued after the queue was drained
+		select {
+		case err := <-pm.ech:
+			return err
+		default:
+			return errGroupCommitStopped
+		}
+	}
+}
+
+func (c *committer) Put(key, value string) error {
+	return c.apply(Mutation{
+		Key:   key,
+		Value: value,
+	})
+}
+
+func (c *committer) Delete(key string) error {
+	return c.apply(Mutation{
+		Key:    key,
+		Delete: true,
+	})
+}
+
+func (c *committer) Start(ctx context.Context) <-chan error {
+	ech := make(chan error, 1)
+	c.eg.Go(safety.RecoverFunc(func() error {
+		defer close(ech)
+		defer c.stop(ctx.Err)
+		batch := make([]*pendingMutation, 0, c.size)
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case pm := <-c.ch:
+				batch = append(batch[:0], pm)
+			}
+			timer := time.NewTimer(c.window)
+		gather:
+			for len(batch) < c.size {
+				select {
+				case pm := <-c.ch:
+					batch = append(batch, pm)
+				case <-timer.C:
+					break gather
+				}
+			}
+			timer.Stop()
+			c.commit(batch)
+		}
+	}))
+	return ech
+}
+
+func (c *committer) commit(batch []*pendingMutation) {
+	ms := make([]Mutation, 0, len(batch))
+	for _, pm := range batch {
+		ms = append(ms, pm.Mutation)
+	}
+	for i, err := range c.batcher.Write(ms) {
+		batch[i].ech <- err
+	}
+}
+
+// stop rejects the new mutations and fails the queued ones.
+func (c *committer) stop(cause func() error) {
+	close(c.done)
+	for {
+		select {
+		case pm := <-c.ch:
+			pm.ech <- cause()
+		default:
+			return
+		}
+	}
+}
+
+// Commit bypasses the grouping, the transaction is already one batch.
+func (c *committer) Commit(ms []Mutation) error {
+	return Commit(c.HaloDB, ms)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/timeutil"
+)
+
+type CommitterOption func(*committer) error
+
+var (
+	defaultCommitterOpts = []CommitterOption{
+		WithCommitWindow("1ms"),
+		WithCommitBatchSize(128),
+		WithCommitterErrGroup(errgroup.Get()),
+	}
+)
+
+func WithCommitWindow(dur string) CommitterOption {
+	return func(c *committer) error {
+		if len(dur) == 0 {
+			return nil
+		}
+		d, err := timeutil.Parse(dur)
+		if err != nil {
+			return err
+		}
+		c.window = d
+		return nil
+	}
+}
+
+func WithCommitBatchSize(size int) CommitterOption {
+	return func(c *committer) error {
+		if size <= 0 {
+			return nil
+		}
+		c.size = size
+		return nil
+	}
+}
+
+func WithCommitterErrGroup(eg errgroup.Group) CommitterOption {
+	return func(c *committer) error {
+		if eg != nil {
+			c.eg = eg
+		}
+		return nil
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+// batchDB is a memDB which records the size of each batch and fails the writes of the keys in fails.
+type batchDB struct {
+	*memDB
+	fails   map[string]bool
+	bmu     sync.Mutex
+	batches []int
+}
+
+func (d *batchDB) Write(ms []Mutation) []error {
+	d.bmu.Lock()
+	d.batches = append(d.batches, len(ms))
+	d.bmu.Unlock()
+	errs := make([]error, len(ms))
+	for i, m := range ms {
+		switch {
+		case d.fails[m.Key]:
+			errs[i] = errors.Errorf("failed to write %s", m.Key)
+		case m.Delete:
+			errs[i] = d.memDB.Delete(m.Key)
+		default:
+			errs[i] = d.memDB.Put(m.Key, m.Value)
+		}
+	}
+	return errs
+}
+
+func Test_committer_Put(t *testing.T) {
+	type args struct {
+		// keys are written concurrently
+		keys []string
+	}
+	type fields struct {
+		window string
+		size   int
+		fails  map[string]bool
+	}
+	type want struct {
+		// batches are the sizes of the batches, sorted
+		batches []int
+		// errs are the keys whose write fails
+		errs map[string]bool
+		// elapsed bounds the time of all the writes
+		minElapsed time.Duration
+		maxElapsed time.Duration
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, []int, map[string]bool, time.Duration) error
+	}
+	defaultCheckFunc := func(w want, batches []int, errs map[string]bool, elapsed time.Duration) error {
+		if !reflect.DeepEqual(batches, w.batches) {
+			return errors.Errorf("got batches = %v, want %v", batches, w.batches)
+		}
+		if !reflect.DeepEqual(errs, w.errs) {
+			return errors.Errorf("got failed keys = %v, want %v", errs, w.errs)
+		}
+		if elapsed < w.minElapsed || (w.maxElapsed != 0 && elapsed > w.maxElapsed) {
+			return errors.Errorf("got elapsed = %v, want between %v and %v", elapsed, w.minElapsed, w.maxElapsed)
+		}
+		return nil
+	}
+	keys := func(n int) []string {
+		ks := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			ks = append(ks, "k"+strconv.Itoa(i))
+		}
+		return ks
+	}
+	tests := []test{
+		{
+			name: "a write waits for the window before it is applied",
+			args: args{
+				keys: keys(1),
+			},
+			fields: fields{
+				window: "50ms",
+				size:   128,
+			},
+			want: want{
+				batches:    []int{1},
+				errs:       map[string]bool{},
+				minElapsed: 50 * time.Millisecond,
+			},
+		},
+		{
+			name: "the concurrent writes within the window are applied in one batch",
+			args: args{
+				keys: keys(8),
+			},
+			fields: fields{
+				window: "500ms",
+				size:   128,
+			},
+			want: want{
+				batches: []int{8},
+				errs:    map[string]bool{},
+			},
+		},
+		{
+			name: "a batch is applied as soon as it reaches the batch size",
+			args: args{
+				keys: keys(8),
+			},
+			fields: fields{
+				window: "1m",
+				size:   4,
+			},
+			want: want{
+				batches:    []int{4, 4},
+				errs:       map[string]bool{},
+				maxElapsed: 30 * time.Second,
+			},
+		},
+		{
+			name: "the error of a write is returned to its writer only",
+			args: args{
+				keys: keys(3),
+			},
+			fields: fields{
+				window: "500ms",
+				size:   128,
+				fails: map[string]bool{
+					"k1": true,
+				},
+			},
+			want: want{
+				batches: []int{3},
+				errs: map[string]bool{
+					"k1": true,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := &batchDB{
+				memDB: newMemDB(nil),
+				fails: test.fields.fails,
+			}
+			c, err := NewCommitter(db, WithCommitWindow(test.fields.window), WithCommitBatchSize(test.fields.size))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			ech := c.Start(ctx)
+			defer func() {
+				cancel()
+				for range ech {
+				}
+			}()
+
+			start := time.Now()
+			var (
+				wg   sync.WaitGroup
+				mu   sync.Mutex
+				errs = make(map[string]bool)
+			)
+			for _, key := range test.args.keys {
+				key := key
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := c.Put(key, "v"); err != nil {
+						mu.Lock()
+						errs[key] = true
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+			elapsed := time.Since(start)
+			db.bmu.Lock()
+			batches := append([]int(nil), db.batches...)
+			db.bmu.Unlock()
+			sort.Ints(batches)
+			if err := checkFunc(test.want, batches, errs, elapsed); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_committer_concurrent(t *testing.T) {
+	c, err := NewCommitter(&batchDB{
+		memDB: newMemDB(nil),
+	}, WithCommitWindow("10us"), WithCommitBatchSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ech := c.Start(ctx)
+	defer func() {
+		cancel()
+		for range ech {
+		}
+	}()
+	if err := checkReadAfterWrite(c, []string{"a", "b", "c", "d"}, 20, 8); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/rinx/vald-meta-halodb/internal/compress"
+	"github.com/rinx/vald-meta-halodb/internal/config"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+// compressHeader prefixes the values written by the compressor.
+// The header is followed by the codec name, ':' and the base64 encoded payload,
+// because the values are passed to libhalodb as C strings.
+const (
+	compressHeader = "\x1bcmp:"
+	rawCodec       = "raw"
+)
+
+type compressor struct {
+	HaloDB
+	algorithm string
+	level     int
+	threshold int
+	writer    compress.Compressor
+	readers   map[string]compress.Compressor
+}
+
+// NewCompressor returns a HaloDB which compresses the values larger than the threshold.
+// Values are always decompressed on Get, so entries written with another codec
+// or before the compression was enabled stay readable.
+func NewCompressor(h HaloDB, opts ...CompressorOption) (HaloDB, error) {
+	c := &compressor{
+		HaloDB: h,
+	}
+
+	for _, opt := range append(defaultCompressorOpts, opts...) {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	zstd, err := compress.NewZstd()
+	if err != nil {
+		return nil, err
+	}
+	lz4, err := compress.NewLZ4()
+	if err != nil {
+		return nil, err
+	}
+	c.readers = map[string]compress.Compressor{
+		config.ZSTD.String(): zstd,
+		config.LZ4.String():  lz4,
+	}
+
+	switch config.CompressAlgorithm(c.algorithm) {
+	case config.ZSTD:
+		c.writer, err = compress.NewZstd(compress.WithZstdCompressionLevel(c.level))
+		if err != nil {
+			return nil, err
+		}
+	case config.LZ4:
+		c.writer = lz4
+	default:
+		if len(c.algorithm) != 0 {
+			return nil, errors.ErrCompressorNameNotFound(c.algorithm)
+		}
+	}
+	c.algorithm = config.CompressAlgorithm(c.algorithm).String()
+
+	return c, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func (c *compressor) encode(value string) (string, error) {
+	if c.writer == nil || len(value) < c.threshold {
+		if strings.HasPrefix(value, compressHeader) {
+			return compressHeader + rawCodec + ":" + value, nil
+		}
+		return value, nil
+	}
+
+	buf := new(bytes.Buffer)
+	w, err := c.writer.Writer(nopCloser{buf})
+	if err != nil {
+		return "", err
+	}
+	_, err = w.Write([]byte(value))
+	if err != nil {
+		return "", err
+	}
+	err = w.Close()
+	if err != nil {
+		return "", err
+	}
+
+	res := compressHeader + c.algorithm + ":" + base64.StdEncoding.EncodeToString(buf.Bytes())
+	if len(res) >= len(value) && !strings.HasPrefix(value, compressHeader) {
+		return value, nil
+	}
+	return res, nil
+}
+
+func (c *compressor) decode(raw string) (string, error) {
+	if !strings.HasPrefix(raw, compressHeader) {
+		return raw, nil
+	}
+	rest := raw[len(compressHeader):]
+	i := strings.IndexByte(rest, ':')
+	if i < 0 {
+		return raw, nil
+	}
+	codec, payload := rest[:i], rest[i+1:]
+	if codec == rawCodec {
+		return payload, nil
+	}
+
+	r, ok := c.readers[codec]
+	if !ok {
+		return "", errors.ErrCompressorNameNotFound(codec)
+	}
+	bs, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", err
+	}
+	zr, err := r.Reader(bytes.NewReader(bs))
+	if err != nil {
+		return "", err
+	}
+	res, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	// *zstd.Decoder releases its goroutines by Close without an error, the lz4 reader has nothing to release
+	if rc, ok := zr.(interface{ Close() }); ok {
+		rc.Close()
+	}
+	return string(res), nil
+}
+
+func (c *compressor) Put(key, value string) error {
+	v, err := c.encode(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to compress %s", key)
+	}
+	return c.HaloDB.Put(key, v)
+}
+
+func (c *compressor) Get(key string) (string, error) {
+	raw, err := c.HaloDB.Get(key)
+	if err != nil {
+		return "", err
+	}
+	v, err := c.decode(raw)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decompress %s", key)
+	}
+	return v, nil
+}
+
+func (c *compressor) Commit(ms []Mutation) error {
+	cms := make([]Mutation, 0, len(ms))
+	for _, m := range ms {
+		if !m.Delete {
+			v, err := c.encode(m.Value)
+			if err != nil {
+				return errors.Wrapf(err, "failed to compress %s", m.Key)
+			}
+			m.Value = v
+		}
+		cms = append(cms, m)
+	}
+	return Commit(c.HaloDB, cms)
+}
@@ -0,0 +1,33 @@
+package service
+
+type CompressorOption func(*compressor) error
+
+var (
+	defaultCompressorOpts = []CompressorOption{
+		WithCompressThreshold(1024),
+	}
+)
+
+func WithCompressAlgorithm(algorithm string) CompressorOption {
+	return func(c *compressor) error {
+		c.algorithm = algorithm
+		return nil
+	}
+}
+
+func WithCompressionLevel(level int) CompressorOption {
+	return func(c *compressor) error {
+		c.level = level
+		return nil
+	}
+}
+
+func WithCompressThreshold(size int) CompressorOption {
+	return func(c *compressor) error {
+		if size <= 0 {
+			return nil
+		}
+		c.threshold = size
+		return nil
+	}
+}
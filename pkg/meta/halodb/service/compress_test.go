@@ -0,0 +1,195 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+func Test_compressor_Get(t *testing.T) {
+	type args struct {
+		value string
+	}
+	type fields struct {
+		// writeOpts are the options of the compressor writing the value, readOpts the ones of the compressor reading it
+		writeOpts []CompressorOption
+		readOpts  []CompressorOption
+	}
+	type want struct {
+		// stored is the prefix of the entry in HaloDB, the entry is the value itself when it is empty
+		stored string
+		// shorter reports whether the entry is shorter than the value
+		shorter bool
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, string, string, error, string) error
+	}
+	defaultCheckFunc := func(w want, value, val string, err error, stored string) error {
+		if err != nil {
+			return errors.Errorf("got error = %v, want nil", err)
+		}
+		if val != value {
+			return errors.Errorf("got value = %q, want %q", val, value)
+		}
+		if len(w.stored) == 0 {
+			if stored != value {
+				return errors.Errorf("got entry = %q, want the value", stored)
+			}
+			return nil
+		}
+		if !strings.HasPrefix(stored, w.stored) {
+			return errors.Errorf("got entry = %q, want prefix %q", stored, w.stored)
+		}
+		if (len(stored) < len(value)) != w.shorter {
+			return errors.Errorf("got entry of %d bytes for a value of %d bytes, want shorter %v", len(stored), len(value), w.shorter)
+		}
+		return nil
+	}
+	long := strings.Repeat("vald-meta-halodb ", 128)
+	random := make([]byte, 2048)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	incompressible := base64.StdEncoding.EncodeToString(random)
+	tests := []test{
+		{
+			name: "a value below the threshold is stored as it is",
+			args: args{
+				value: "short",
+			},
+			fields: fields{
+				writeOpts: []CompressorOption{WithCompressAlgorithm("zstd")},
+				readOpts:  []CompressorOption{WithCompressAlgorithm("zstd")},
+			},
+		},
+		{
+			name: "a value above the threshold is compressed by zstd",
+			args: args{
+				value: long,
+			},
+			fields: fields{
+				writeOpts: []CompressorOption{WithCompressAlgorithm("zstd")},
+				readOpts:  []CompressorOption{WithCompressAlgorithm("zstd")},
+			},
+			want: want{
+				stored:  compressHeader + "zstd:",
+				shorter: true,
+			},
+		},
+		{
+			name: "a value above the threshold is compressed by lz4",
+			args: args{
+				value: long,
+			},
+			fields: fields{
+				writeOpts: []CompressorOption{WithCompressAlgorithm("lz4")},
+				readOpts:  []CompressorOption{WithCompressAlgorithm("lz4")},
+			},
+			want: want{
+				stored:  compressHeader + "lz4:",
+				shorter: true,
+			},
+		},
+		{
+			name: "a value which does not get shorter is stored as it is",
+			args: args{
+				value: incompressible,
+			},
+			fields: fields{
+				writeOpts: []CompressorOption{WithCompressAlgorithm("zstd")},
+				readOpts:  []CompressorOption{WithCompressAlgorithm("zstd")},
+			},
+		},
+		{
+			name: "a value starting with the header is escaped",
+			args: args{
+				value: compressHeader + "zstd:not compressed",
+			},
+			fields: fields{
+				writeOpts: []CompressorOption{WithCompressAlgorithm("zstd")},
+				readOpts:  []CompressorOption{WithCompressAlgorithm("zstd")},
+			},
+			want: want{
+				stored: compressHeader + rawCodec + ":" + compressHeader + "zstd:",
+			},
+		},
+		{
+			name: "a value starting with the header is escaped while the compression is disabled",
+			args: args{
+				value: compressHeader + "lz4:not compressed",
+			},
+			want: want{
+				stored: compressHeader + rawCodec + ":" + compressHeader + "lz4:",
+			},
+		},
+		{
+			name: "a value compressed by zstd is read after the codec is changed to lz4",
+			args: args{
+				value: long,
+			},
+			fields: fields{
+				writeOpts: []CompressorOption{WithCompressAlgorithm("zstd")},
+				readOpts:  []CompressorOption{WithCompressAlgorithm("lz4")},
+			},
+			want: want{
+				stored:  compressHeader + "zstd:",
+				shorter: true,
+			},
+		},
+		{
+			name: "a value compressed by lz4 is read after the compression is disabled",
+			args: args{
+				value: long,
+			},
+			fields: fields{
+				writeOpts: []CompressorOption{WithCompressAlgorithm("lz4")},
+			},
+			want: want{
+				stored:  compressHeader + "lz4:",
+				shorter: true,
+			},
+		},
+		{
+			name: "a value written before the compression is enabled is read",
+			args: args{
+				value: long,
+			},
+			fields: fields{
+				readOpts: []CompressorOption{WithCompressAlgorithm("zstd")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := newMemDB(nil)
+			w, err := NewCompressor(db, test.fields.writeOpts...)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err := w.Put("key", test.args.value); err != nil {
+				tt.Fatal(err)
+			}
+			c, err := NewCompressor(db, test.fields.readOpts...)
+			if err != nil {
+				tt.Fatal(err)
+			}
+
+			val, err := c.Get("key")
+			if err := checkFunc(test.want, test.args.value, val, err, db.entries()["key"]); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+)
+
+// encryptHeader prefixes the encrypted values and keys.
+// The header is followed by the key id, ':' and the base64 encoded nonce and ciphertext.
+const encryptHeader = "\x1benc:"
+
+type encryptionKey struct {
+	id   string
+	aead cipher.AEAD
+	mac  []byte
+}
+
+type encryptor struct {
+	HaloDB
+	// locks serialize the writes of each key so no write interleaves the check and the rewrite of a re-encryption
+	locks       [generationStripes]sync.Mutex
+	keys        []encryptionKey
+	encryptKeys bool
+}
+
+// NewEncryptor returns a HaloDB which encrypts the values with AES-GCM.
+// The first key is used for writing and the others are only used for reading,
+// entries read with an older key or stored in plaintext are re-encrypted with the first key.
+// HaloDB cannot list its keys, so the entries which are not read are not re-encrypted.
+// When key encryption is enabled, the keys are encrypted deterministically so they can still be looked up.
+func NewEncryptor(h HaloDB, opts ...EncryptorOption) (HaloDB, error) {
+	e := &encryptor{
+		HaloDB: h,
+	}
+
+	for _, opt := range append(defaultEncryptorOpts, opts...) {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(e.keys) == 0 {
+		return nil, errors.New("no encryption keys")
+	}
+
+	return e, nil
+}
+
+// parseEncryptionKeys parses the "<id>:<base64 key>" entries separated by newlines or commas.
+func parseEncryptionKeys(keys string) ([]encryptionKey, error) {
+	res := make([]encryptionKey, 0)
+	for _, entry := range strings.FieldsFunc(keys, func(r rune) bool {
+		return r == '\n' || r == ','
+	}) {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		i := strings.IndexByte(entry, ':')
+		if i <= 0 {
+			return nil, errors.Errorf("invalid encryption key entry %s", entry)
+		}
+		id := entry[:i]
+		raw, err := base64.StdEncoding.DecodeString(entry[i+1:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid encryption key %s", id)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid encryption key %s", id)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		mac := hmac.New(sha256.New, raw)
+		mac.Write([]byte("meta-halodb key nonce"))
+		res = append(res, encryptionKey{
+			id:   id,
+			aead: aead,
+			mac:  mac.Sum(nil),
+		})
+	}
+	return res, nil
+}
+
+func (e *encryptor) key(id string) (int, bool) {
+	for i, k := range e.keys {
+		if k.id == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func (e *encryptor) lock(key string) *sync.Mutex {
+	return &e.locks[hash(key)%generationStripes]
+}
+
+func (e *encryptor) encryptKey(k encryptionKey, key string) string {
+	mac := hmac.New(sha256.New, k.mac)
+	mac.Write([]byte(key))
+	nonce := mac.Sum(nil)[:k.aead.NonceSize()]
+	return encryptHeader + k.id + ":" +
+		base64.RawURLEncoding.EncodeToString(k.aead.Seal(nonce, nonce, []byte(key), nil))
+}
+
+// storageKeys returns the candidate keys in HaloDB for key, the primary key comes first.
+func (e *encryptor) storageKeys(key string) []string {
+	if !e.encryptKeys {
+		return []string{key}
+	}
+	sks := make([]string, 0, len(e.keys)+1)
+	for _, k := range e.keys {
+		sks = append(sks, e.encryptKey(k, key))
+	}
+	return append(sks, key)
+}
+
+func (e *encryptor) encryptValue(key, value string) (string, error) {
+	k := e.keys[0]
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return encryptHeader + k.id + ":" +
+		base64.StdEncoding.EncodeToString(k.aead.Seal(nonce, nonce, []byte(value), []byte(key))), nil
+}
+
+// decryptValue returns the plaintext and the position of the key used, -1 for plaintext values.
+func (e *encryptor) decryptValue(key, raw string) (string, int, error) {
+	if !strings.HasPrefix(raw, encryptHeader) {
+		return raw, -1, nil
+	}
+	rest := raw[len(encryptHeader):]
+	i := strings.IndexByte(rest, ':')
+	if i < 0 {
+		return raw, -1, nil
+	}
+	idx, ok := e.key(rest[:i])
+	if !ok {
+		return "", -1, errors.Errorf("unknown encryption key %s", rest[:i])
+	}
+	bs, err := base64.StdEncoding.DecodeString(rest[i+1:])
+	if err != nil {
+		return "", -1, err
+	}
+	aead := e.keys[idx].aead
+	if len(bs) < aead.NonceSize() {
+		return "", -1, errors.New("invalid ciphertext")
+	}
+	plain, err := aead.Open(nil, bs[:aead.NonceSize()], bs[aead.NonceSize():], []byte(key))
+	if err != nil {
+		return "", -1, err
+	}
+	return string(plain), idx, nil
+}
+
+func (e *encryptor) Put(key, value string) error {
+	v, err := e.encryptValue(key, value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt %s", key)
+	}
+
+	l := e.lock(key)
+	l.Lock()
+	defer l.Unlock()
+
+	return e.HaloDB.Put(e.storageKeys(key)[0], v)
+}
+
+func (e *encryptor) Get(key string) (string, error) {
+	var lerr error
+	for i, sk := range e.storageKeys(key) {
+		raw, err := e.HaloDB.Get(sk)
+		if err != nil {
+			lerr = err
+			continue
+		}
+		v, idx, err := e.decryptValue(key, raw)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to decrypt %s", key)
+		}
+		if i != 0 || idx != 0 {
+			e.reencrypt(key, sk, raw, v)
+		}
+		return v, nil
+	}
+	return "", lerr
+}
+
+// reencrypt rewrites the entry found at sk with the primary key unless it was updated meanwhile.
+func (e *encryptor) reencrypt(key, sk, raw, value string) {
+	l := e.lock(key)
+	l.Lock()
+	defer l.Unlock()
+
+	cur, err := e.HaloDB.Get(sk)
+	if err != nil || cur != raw {
+		return
+	}
+	primary := e.storageKeys(key)[0]
+	if sk != primary {
+		// a newer entry was written with the primary key
+		if _, err = e.HaloDB.Get(primary); err == nil {
+			return
+		}
+	}
+	v, err := e.encryptValue(key, value)
+	if err == nil {
+		err = e.HaloDB.Put(primary, v)
+	}
+	if err == nil && sk != primary {
+		err = e.HaloDB.Delete(sk)
+	}
+	if err != nil {
+		log.Warnf("[Encryptor]\tfailed to re-encrypt %s\t%+v", key, err)
+	}
+}
+
+func (e *encryptor) Delete(key string) (err error) {
+	l := e.lock(key)
+	l.Lock()
+	defer l.Unlock()
+
+	var deleted bool
+	for _, sk := range e.storageKeys(key) {
+		derr := e.HaloDB.Delete(sk)
+		if derr != nil {
+			if err == nil {
+				err = derr
+			}
+			continue
+		}
+		deleted = true
+	}
+	if deleted {
+		return nil
+	}
+	return err
+}
+
+// Commit holds the locks of all the keys, so no re-encryption interleaves the transaction.
+func (e *encryptor) Commit(ms []Mutation) error {
+	ems := make([]Mutation, 0, len(ms))
+	for _, m := range ms {
+		if m.Delete {
+			for _, sk := range e.storageKeys(m.Key) {
+				ems = append(ems, Mutation{
+					Key:    sk,
+					Delete: true,
+				})
+			}
+			continue
+		}
+		v, err := e.encryptValue(m.Key, m.Value)
+		if err != nil {
+			return errors.Wrapf(err, "failed to encrypt %s", m.Key)
+		}
+		ems = append(ems, Mutation{
+			Key:   e.storageKeys(m.Key)[0],
+			Value: v,
+		})
+	}
+
+	var held [generationStripes]bool
+	for _, m := range ms {
+		held[hash(m.Key)%generationStripes] = true
+	}
+	// the stripes are locked in order so two commits cannot deadlock
+	for i := range held {
+		if held[i] {
+			e.locks[i].Lock()
+			defer e.locks[i].Unlock()
+		}
+	}
+
+	return Commit(e.HaloDB, ems)
+}
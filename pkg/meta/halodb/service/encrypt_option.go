@@ -0,0 +1,27 @@
+package service
+
+type EncryptorOption func(*encryptor) error
+
+var (
+	defaultEncryptorOpts = []EncryptorOption{}
+)
+
+// WithEncryptionKeys sets the "<id>:<base64 key>" entries separated by newlines or commas.
+// The first entry is the primary key.
+func WithEncryptionKeys(keys string) EncryptorOption {
+	return func(e *encryptor) error {
+		ks, err := parseEncryptionKeys(keys)
+		if err != nil {
+			return err
+		}
+		e.keys = ks
+		return nil
+	}
+}
+
+func WithKeyEncryption(enabled bool) EncryptorOption {
+	return func(e *encryptor) error {
+		e.encryptKeys = enabled
+		return nil
+	}
+}
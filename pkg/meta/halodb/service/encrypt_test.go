@@ -0,0 +1,303 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+func testEncryptionKey(id string, b byte) string {
+	return id + ":" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string([]byte{b}), 16)))
+}
+
+func Test_encryptor_Get(t *testing.T) {
+	type args struct {
+		key string
+	}
+	type fields struct {
+		kvs map[string]string
+		// writeKeys are the keys of the encryptor writing the puts, keys the ones of the encryptor reading them
+		writeKeys   string
+		keys        string
+		encryptKeys bool
+		puts        map[string]string
+	}
+	type want struct {
+		val string
+		err error
+		// keyID is the id of the key of the entry in HaloDB after the read, empty for a plaintext entry
+		keyID string
+		// storage is the number of HaloDB entries after the read
+		storage int
+		// encryptedKeys reports whether the keys of the entries are encrypted
+		encryptedKeys bool
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, string, error, map[string]string) error
+	}
+	defaultCheckFunc := func(w want, val string, err error, kvs map[string]string) error {
+		if (err == nil) != (w.err == nil) || (err != nil && !strings.HasPrefix(err.Error(), w.err.Error())) {
+			return errors.Errorf("got error = %v, want %v", err, w.err)
+		}
+		if val != w.val {
+			return errors.Errorf("got value = %v, want %v", val, w.val)
+		}
+		if len(kvs) != w.storage {
+			return errors.Errorf("got entries = %v, want %d entries", kvs, w.storage)
+		}
+		for k, v := range kvs {
+			if strings.HasPrefix(k, encryptHeader) != w.encryptedKeys {
+				return errors.Errorf("got entry %s = %s, want encrypted key %v", k, v, w.encryptedKeys)
+			}
+			if len(w.keyID) != 0 && !strings.HasPrefix(v, encryptHeader+w.keyID+":") {
+				return errors.Errorf("got entry %s = %s, want encrypted with %s", k, v, w.keyID)
+			}
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "a value is encrypted and decrypted",
+			args: args{
+				key: "key",
+			},
+			fields: fields{
+				writeKeys: testEncryptionKey("k1", 1),
+				keys:      testEncryptionKey("k1", 1),
+				puts: map[string]string{
+					"key": "secret",
+				},
+			},
+			want: want{
+				val:     "secret",
+				keyID:   "k1",
+				storage: 1,
+			},
+		},
+		{
+			name: "a value encrypted with an old key is read and re-encrypted with the first key",
+			args: args{
+				key: "key",
+			},
+			fields: fields{
+				writeKeys: testEncryptionKey("k1", 1),
+				keys:      testEncryptionKey("k2", 2) + "," + testEncryptionKey("k1", 1),
+				puts: map[string]string{
+					"key": "secret",
+				},
+			},
+			want: want{
+				val:     "secret",
+				keyID:   "k2",
+				storage: 1,
+			},
+		},
+		{
+			name: "a value encrypted with a removed key cannot be read",
+			args: args{
+				key: "key",
+			},
+			fields: fields{
+				writeKeys: testEncryptionKey("k1", 1),
+				keys:      testEncryptionKey("k2", 2),
+				puts: map[string]string{
+					"key": "secret",
+				},
+			},
+			want: want{
+				err:     errors.New("failed to decrypt key: unknown encryption key k1"),
+				keyID:   "k1",
+				storage: 1,
+			},
+		},
+		{
+			name: "a plaintext value is read and encrypted",
+			args: args{
+				key: "key",
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"key": "secret",
+				},
+				keys: testEncryptionKey("k1", 1),
+			},
+			want: want{
+				val:     "secret",
+				keyID:   "k1",
+				storage: 1,
+			},
+		},
+		{
+			name: "an encrypted key is looked up",
+			args: args{
+				key: "key",
+			},
+			fields: fields{
+				writeKeys:   testEncryptionKey("k1", 1),
+				keys:        testEncryptionKey("k1", 1),
+				encryptKeys: true,
+				puts: map[string]string{
+					"key": "secret",
+				},
+			},
+			want: want{
+				val:           "secret",
+				keyID:         "k1",
+				storage:       1,
+				encryptedKeys: true,
+			},
+		},
+		{
+			name: "a key encrypted with an old key is moved to the first key",
+			args: args{
+				key: "key",
+			},
+			fields: fields{
+				writeKeys:   testEncryptionKey("k1", 1),
+				keys:        testEncryptionKey("k2", 2) + "," + testEncryptionKey("k1", 1),
+				encryptKeys: true,
+				puts: map[string]string{
+					"key": "secret",
+				},
+			},
+			want: want{
+				val:           "secret",
+				keyID:         "k2",
+				storage:       1,
+				encryptedKeys: true,
+			},
+		},
+		{
+			name: "a plaintext key is moved to the first key",
+			args: args{
+				key: "key",
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"key": "secret",
+				},
+				keys:        testEncryptionKey("k1", 1),
+				encryptKeys: true,
+			},
+			want: want{
+				val:           "secret",
+				keyID:         "k1",
+				storage:       1,
+				encryptedKeys: true,
+			},
+		},
+		{
+			name: "a missing key is not found",
+			args: args{
+				key: "key",
+			},
+			fields: fields{
+				keys:        testEncryptionKey("k1", 1),
+				encryptKeys: true,
+			},
+			want: want{
+				err: errNotFound("key"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := newMemDB(test.fields.kvs)
+			if len(test.fields.puts) != 0 {
+				w, err := NewEncryptor(db, WithEncryptionKeys(test.fields.writeKeys), WithKeyEncryption(test.fields.encryptKeys))
+				if err != nil {
+					tt.Fatal(err)
+				}
+				for k, v := range test.fields.puts {
+					if err := w.Put(k, v); err != nil {
+						tt.Fatal(err)
+					}
+				}
+			}
+			e, err := NewEncryptor(db, WithEncryptionKeys(test.fields.keys), WithKeyEncryption(test.fields.encryptKeys))
+			if err != nil {
+				tt.Fatal(err)
+			}
+
+			val, err := e.Get(test.args.key)
+			if err := checkFunc(test.want, val, err, db.entries()); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_encryptor_encryptKey(t *testing.T) {
+	type args struct {
+		keys []string
+	}
+	type want struct {
+		same bool
+	}
+	type test struct {
+		name      string
+		args      args
+		want      want
+		checkFunc func(want, []string) error
+	}
+	defaultCheckFunc := func(w want, sks []string) error {
+		for i := 1; i < len(sks); i++ {
+			if (sks[i] == sks[0]) != w.same {
+				return errors.Errorf("got storage keys = %v, want same %v", sks, w.same)
+			}
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "a key is always encrypted to the same storage key",
+			args: args{
+				keys: []string{"key", "key", "key"},
+			},
+			want: want{
+				same: true,
+			},
+		},
+		{
+			name: "different keys are encrypted to different storage keys",
+			args: args{
+				keys: []string{"a", "b", "c"},
+			},
+			want: want{
+				same: false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			// each key is encrypted by a new encryptor, so the storage key does not depend on its state
+			sks := make([]string, 0, len(test.args.keys))
+			for _, key := range test.args.keys {
+				h, err := NewEncryptor(newMemDB(nil), WithEncryptionKeys(testEncryptionKey("k1", 1)), WithKeyEncryption(true))
+				if err != nil {
+					tt.Fatal(err)
+				}
+				sks = append(sks, h.(*encryptor).storageKeys(key)[0])
+			}
+			if err := checkFunc(test.want, sks); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
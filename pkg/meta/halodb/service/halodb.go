@@ -17,6 +17,27 @@ import (
 type haloDB struct {
 	isolate *C.graal_isolate_t
 	mu      sync.Mutex
+	opened  bool
+}
+
+// notFoundError is returned by Get when no value is stored for the key.
+type notFoundError struct {
+	key string
+}
+
+func (e *notFoundError) Error() string {
+	return "failed to get " + e.key
+}
+
+func errNotFound(key string) error {
+	return &notFoundError{
+		key: key,
+	}
+}
+
+func isNotFound(err error) bool {
+	_, ok := errors.Cause(err).(*notFoundError)
+	return ok
 }
 
 type HaloDB interface {
@@ -28,6 +49,25 @@ type HaloDB interface {
 	Close() error
 }
 
+// Mutation is a put, or a delete when Delete is true.
+type Mutation struct {
+	Key    string
+	Value  string
+	Delete bool
+}
+
+// Batcher applies the mutations in order with one lock and one thread attachment.
+// libhalodb has no batch API, so they are not applied atomically and a failed mutation does not stop the others.
+type Batcher interface {
+	Write(ms []Mutation) []error
+}
+
+// Compactor pauses and resumes the background compaction of HaloDB.
+type Compactor interface {
+	PauseCompaction() error
+	ResumeCompaction() error
+}
+
 func New() (HaloDB, error) {
 	var isolate *C.graal_isolate_t
 	var thread *C.graal_isolatethread_t
@@ -75,6 +115,32 @@ func (h *haloDB) resumeCompaction(thread *C.graal_isolatethread_t) error {
 	return nil
 }
 
+func (h *haloDB) PauseCompaction() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	thread, err := h.attachThread()
+	if err != nil {
+		return err
+	}
+	defer C.free(unsafe.Pointer(thread))
+
+	return h.pauseCompaction(thread)
+}
+
+func (h *haloDB) ResumeCompaction() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	thread, err := h.attachThread()
+	if err != nil {
+		return err
+	}
+	defer C.free(unsafe.Pointer(thread))
+
+	return h.resumeCompaction(thread)
+}
+
 func (h *haloDB) Open(path string) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -91,6 +157,7 @@ func (h *haloDB) Open(path string) error {
 	if C.halodb_open(thread, csPath) != 0 {
 		return errors.New("failed to open halodb")
 	}
+	h.opened = true
 
 	return nil
 }
@@ -105,6 +172,10 @@ func (h *haloDB) Put(key, value string) error {
 	}
 	defer C.free(unsafe.Pointer(thread))
 
+	return h.put(thread, key, value)
+}
+
+func (h *haloDB) put(thread *C.graal_isolatethread_t, key, value string) error {
 	csKey, csValue := C.CString(key), C.CString(value)
 	defer func() {
 		C.free(unsafe.Pointer(csKey))
@@ -118,6 +189,30 @@ func (h *haloDB) Put(key, value string) error {
 	return nil
 }
 
+func (h *haloDB) Write(ms []Mutation) []error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	errs := make([]error, len(ms))
+	thread, err := h.attachThread()
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	defer C.free(unsafe.Pointer(thread))
+
+	for i, m := range ms {
+		if m.Delete {
+			errs[i] = h.delete(thread, m.Key)
+		} else {
+			errs[i] = h.put(thread, m.Key, m.Value)
+		}
+	}
+	return errs
+}
+
 func (h *haloDB) Get(key string) (string, error) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -133,7 +228,7 @@ func (h *haloDB) Get(key string) (string, error) {
 
 	res := C.GoString(C.halodb_get(thread, csKey))
 	if res == "" {
-		return "", errors.Errorf("failed to get %s", key)
+		return "", errNotFound(key)
 	}
 
 	return res, nil
@@ -149,6 +244,10 @@ func (h *haloDB) Delete(key string) error {
 	}
 	defer C.free(unsafe.Pointer(thread))
 
+	return h.delete(thread, key)
+}
+
+func (h *haloDB) delete(thread *C.graal_isolatethread_t, key string) error {
 	csKey := C.CString(key)
 	defer C.free(unsafe.Pointer(csKey))
 
@@ -184,13 +283,16 @@ func (h *haloDB) Close() error {
 	}
 	defer C.free(unsafe.Pointer(thread))
 
-	if C.halodb_close(thread) != 0 {
-		return errors.New("failed to close")
+	// the isolate is torn down even when HaloDB was not opened or failed to close
+	var errs error
+	if h.opened && C.halodb_close(thread) != 0 {
+		errs = errors.New("failed to close")
 	}
+	h.opened = false
 
 	if C.graal_detach_all_threads_and_tear_down_isolate(thread) != 0 {
-		return errors.New("failed to detach all threads and teardown isolate")
+		errs = errors.Wrap(errs, "failed to detach all threads and teardown isolate")
 	}
 
-	return nil
+	return errs
 }
@@ -0,0 +1,593 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/internal/safety"
+)
+
+var errJournalStopped = errors.New("journal applier is stopped")
+
+const (
+	journalPut byte = iota + 1
+	journalDelete
+	// journalBatch is one record holding several mutations, which are replayed all together or not at all
+	journalBatch
+)
+
+const maxJournalFieldSize = 1 << 30
+
+type journalEntry struct {
+	Mutation
+	at time.Time
+	// rest is the number of the mutations after this one in its transaction record
+	rest int
+}
+
+type journal struct {
+	HaloDB
+	path       string
+	size       int
+	retry      time.Duration
+	maxRetries int
+	eg         errgroup.Group
+	// mu serializes the appends and guards file and pending
+	mu      sync.Mutex
+	file    *os.File
+	offset  int64
+	pending map[string]*journalEntry
+	queue   chan *journalEntry
+	// slots holds one token for each mutation which is not applied yet
+	slots chan struct{}
+	done  chan struct{}
+	lag   int64
+	// failed is the number of the mutations dropped after maxRetries failed attempts
+	failed int64
+}
+
+// Journal is a HaloDB which acknowledges the mutations once they are fsynced
+// to a local journal and applies them to HaloDB in the background.
+// Reads check the pending mutations first, and the mutations left in the journal
+// are applied on Open, so the acknowledged writes survive a restart.
+type Journal interface {
+	HaloDB
+	Batcher
+	Start(ctx context.Context) <-chan error
+	// Pending returns the number of the mutations waiting to be applied.
+	Pending() int
+	// Lag returns how long the last applied mutation waited in the queue.
+	Lag() time.Duration
+	// Failed returns the number of the mutations dropped since the start because they could not be applied.
+	Failed() int64
+}
+
+func NewJournal(h HaloDB, opts ...JournalOption) (Journal, error) {
+	j := &journal{
+		HaloDB:  h,
+		pending: make(map[string]*journalEntry),
+		done:    make(chan struct{}),
+	}
+
+	for _, opt := range append(defaultJournalOpts, opts...) {
+		if err := opt(j); err != nil {
+			return nil, err
+		}
+	}
+	j.queue = make(chan *journalEntry, j.size)
+	j.slots = make(chan struct{}, j.size)
+
+	return j, nil
+}
+
+func (j *journal) Open(path string) error {
+	err := j.HaloDB.Open(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	err = j.replay(f)
+	if err == nil {
+		err = f.Truncate(0)
+	}
+	if err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to replay journal %s", j.path)
+	}
+
+	j.mu.Lock()
+	j.file = f
+	j.mu.Unlock()
+
+	return nil
+}
+
+// replay applies the mutations recorded in the journal.
+// A torn record at the tail was never acknowledged, so it is dropped.
+func (j *journal) replay(f *os.File) error {
+	r := bufio.NewReader(f)
+	ms := make([]Mutation, 0, j.size)
+	var n int
+	for {
+		rec, err := readJournalRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Warnf("[Journal]\tdropped the torn journal tail\t%+v", err)
+			break
+		}
+		ms = append(ms, rec...)
+		if len(ms) >= j.size {
+			if err = j.write(ms); err != nil {
+				return err
+			}
+			n += len(ms)
+			ms = ms[:0]
+		}
+	}
+	if err := j.write(ms); err != nil {
+		return err
+	}
+	n += len(ms)
+	if n > 0 {
+		log.Infof("[Journal]\treplayed %d mutations", n)
+	}
+	return nil
+}
+
+// write applies the mutations to HaloDB and returns the first error.
+// The mutations after the failed one are not applied, so they can be retried in order.
+func (j *journal) write(ms []Mutation) error {
+	if len(ms) == 0 {
+		return nil
+	}
+	if b, ok := j.HaloDB.(Batcher); ok {
+		for i, err := range b.Write(ms) {
+			if err != nil {
+				return &journalWriteError{applied: i, err: err}
+			}
+		}
+		return nil
+	}
+	for i, m := range ms {
+		var err error
+		if m.Delete {
+			err = j.HaloDB.Delete(m.Key)
+		} else {
+			err = j.HaloDB.Put(m.Key, m.Value)
+		}
+		if err != nil {
+			return &journalWriteError{applied: i, err: err}
+		}
+	}
+	return nil
+}
+
+type journalWriteError struct {
+	applied int
+	err     error
+}
+
+func (e *journalWriteError) Error() string {
+	return e.err.Error()
+}
+
+func appendJournalRecord(buf []byte, m Mutation) []byte {
+	start := len(buf)
+	buf = appendJournalMutation(buf, m)
+	return appendJournalChecksum(buf, start)
+}
+
+// appendJournalBatch appends the mutations as one record with one checksum.
+func appendJournalBatch(buf []byte, ms []Mutation) []byte {
+	start := len(buf)
+	buf = append(buf, journalBatch)
+	var l [binary.MaxVarintLen64]byte
+	buf = append(buf, l[:binary.PutUvarint(l[:], uint64(len(ms)))]...)
+	for _, m := range ms {
+		buf = appendJournalMutation(buf, m)
+	}
+	return appendJournalChecksum(buf, start)
+}
+
+func appendJournalMutation(buf []byte, m Mutation) []byte {
+	op := journalPut
+	if m.Delete {
+		op = journalDelete
+	}
+	buf = append(buf, op)
+	for _, s := range []string{m.Key, m.Value} {
+		var l [binary.MaxVarintLen64]byte
+		buf = append(buf, l[:binary.PutUvarint(l[:], uint64(len(s)))]...)
+		buf = append(buf, s...)
+	}
+	return buf
+}
+
+func appendJournalChecksum(buf []byte, start int) []byte {
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(buf[start:]))
+	return append(buf, sum[:]...)
+}
+
+// journalReader keeps the bytes read for a record to verify its checksum.
+type journalReader struct {
+	*bufio.Reader
+	rec []byte
+}
+
+func (r *journalReader) readByte() (byte, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	r.rec = append(r.rec, b)
+	return b, nil
+}
+
+func (r *journalReader) readUvarint() (uint64, error) {
+	l, err := binary.ReadUvarint(r.Reader)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid journal record")
+	}
+	var lb [binary.MaxVarintLen64]byte
+	r.rec = append(r.rec, lb[:binary.PutUvarint(lb[:], l)]...)
+	return l, nil
+}
+
+func (r *journalReader) readMutation(op byte) (m Mutation, err error) {
+	if op != journalPut && op != journalDelete {
+		return m, errors.Errorf("invalid journal record operation %d", op)
+	}
+	fields := make([]string, 2)
+	for i := range fields {
+		l, err := r.readUvarint()
+		if err != nil {
+			return m, err
+		}
+		if l > maxJournalFieldSize {
+			return m, errors.Errorf("invalid journal record length %d", l)
+		}
+		bs := make([]byte, l)
+		if _, err = io.ReadFull(r.Reader, bs); err != nil {
+			return m, errors.Wrap(err, "invalid journal record")
+		}
+		r.rec = append(r.rec, bs...)
+		fields[i] = string(bs)
+	}
+	return Mutation{
+		Key:    fields[0],
+		Value:  fields[1],
+		Delete: op == journalDelete,
+	}, nil
+}
+
+// readJournalRecord returns the mutations of the next record, it returns io.EOF only at a record boundary.
+func readJournalRecord(br *bufio.Reader) ([]Mutation, error) {
+	r := &journalReader{
+		Reader: br,
+	}
+	op, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	r.rec = append(r.rec, op)
+	var ms []Mutation
+	if op == journalBatch {
+		n, err := r.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > maxJournalFieldSize {
+			return nil, errors.Errorf("invalid journal batch size %d", n)
+		}
+		for i := uint64(0); i < n; i++ {
+			op, err := r.readByte()
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid journal record")
+			}
+			m, err := r.readMutation(op)
+			if err != nil {
+				return nil, err
+			}
+			ms = append(ms, m)
+		}
+	} else {
+		m, err := r.readMutation(op)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+	var sum [4]byte
+	if _, err = io.ReadFull(br, sum[:]); err != nil {
+		return nil, errors.Wrap(err, "invalid journal record")
+	}
+	if binary.BigEndian.Uint32(sum[:]) != crc32.ChecksumIEEE(r.rec) {
+		return nil, errors.New("journal record checksum mismatch")
+	}
+	return ms, nil
+}
+
+func (j *journal) Put(key, value string) error {
+	return j.Write([]Mutation{{
+		Key:   key,
+		Value: value,
+	}})[0]
+}
+
+func (j *journal) Delete(key string) error {
+	return j.Write([]Mutation{{
+		Key:    key,
+		Delete: true,
+	}})[0]
+}
+
+// Write appends the mutations to the journal with one fsync and queues them.
+// It blocks while the queue is full.
+func (j *journal) Write(ms []Mutation) []error {
+	errs := make([]error, 0, len(ms))
+	for len(ms) > 0 {
+		n := len(ms)
+		if n > j.size {
+			n = j.size
+		}
+		err := j.append(ms[:n], false)
+		for i := 0; i < n; i++ {
+			errs = append(errs, err)
+		}
+		ms = ms[n:]
+	}
+	return errs
+}
+
+// Commit appends the mutations as one journal record, so they are replayed all together or not at all.
+// Reads see all of them once it returns, because they are pending until applied.
+// When one of them still fails after maxRetries, the ones after it are dropped with it,
+// but the ones applied before it are kept, since HaloDB cannot roll them back.
+func (j *journal) Commit(ms []Mutation) error {
+	if len(ms) == 0 {
+		return nil
+	}
+	if len(ms) > j.size {
+		return errors.Errorf("transaction of %d mutations exceeds the journal queue size %d", len(ms), j.size)
+	}
+	return j.append(ms, true)
+}
+
+func (j *journal) append(ms []Mutation, batch bool) error {
+	select {
+	case <-j.done:
+		return errJournalStopped
+	default:
+	}
+	for i := range ms {
+		select {
+		case <-j.done:
+			for ; i > 0; i-- {
+				<-j.slots
+			}
+			return errJournalStopped
+		case j.slots <- struct{}{}:
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	err := j.sync(ms, batch)
+	if err != nil {
+		for range ms {
+			<-j.slots
+		}
+		return err
+	}
+
+	now := time.Now()
+	for i, m := range ms {
+		e := &journalEntry{
+			Mutation: m,
+			at:       now,
+		}
+		if batch {
+			e.rest = len(ms) - 1 - i
+		}
+		j.pending[m.Key] = e
+		j.queue <- e
+	}
+	return nil
+}
+
+// sync must be called with j.mu held.
+func (j *journal) sync(ms []Mutation, batch bool) error {
+	if j.file == nil {
+		return errors.New("journal is not opened")
+	}
+	buf := make([]byte, 0, 64*len(ms))
+	if batch {
+		buf = appendJournalBatch(buf, ms)
+	} else {
+		for _, m := range ms {
+			buf = appendJournalRecord(buf, m)
+		}
+	}
+	_, err := j.file.Write(buf)
+	if err == nil {
+		err = j.file.Sync()
+	}
+	if err != nil {
+		// drop the partial records so the following ones stay readable
+		if terr := j.file.Truncate(j.offset); terr != nil {
+			log.Warnf("[Journal]\tfailed to truncate journal\t%+v", terr)
+		}
+		return errors.Wrap(err, "failed to write journal")
+	}
+	j.offset += int64(len(buf))
+	return nil
+}
+
+func (j *journal) Get(key string) (string, error) {
+	j.mu.Lock()
+	e, ok := j.pending[key]
+	j.mu.Unlock()
+	if ok {
+		if e.Delete {
+			return "", errNotFound(key)
+		}
+		return e.Value, nil
+	}
+	return j.HaloDB.Get(key)
+}
+
+func (j *journal) Start(ctx context.Context) <-chan error {
+	ech := make(chan error, 1)
+	j.eg.Go(safety.RecoverFunc(func() error {
+		defer close(ech)
+		defer close(j.done)
+		batch := make([]*journalEntry, 0, j.size)
+		var retries int
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case e := <-j.queue:
+				batch = append(batch[:0], e)
+			}
+		drain:
+			for len(batch) < cap(batch) {
+				select {
+				case e := <-j.queue:
+					batch = append(batch, e)
+				default:
+					break drain
+				}
+			}
+			// a transaction record is applied in one write, its mutations are queued together
+			for batch[len(batch)-1].rest > 0 {
+				batch = append(batch, <-j.queue)
+			}
+			for len(batch) > 0 {
+				ms := make([]Mutation, 0, len(batch))
+				for _, e := range batch {
+					ms = append(ms, e.Mutation)
+				}
+				err := j.write(ms)
+				if err == nil {
+					j.applied(batch)
+					break
+				}
+				werr, ok := err.(*journalWriteError)
+				if ok {
+					if werr.applied > 0 {
+						retries = 0
+					}
+					j.applied(batch[:werr.applied])
+					batch = batch[werr.applied:]
+					err = werr.err
+				}
+				retries++
+				if retries > j.maxRetries {
+					// the mutation is dropped with the rest of its transaction record,
+					// so the following ones are not blocked behind it
+					n := 1 + batch[0].rest
+					log.Errorf("[Journal]\tdropped %d mutations from %s after %d failed attempts\t%+v", n, batch[0].Key, retries, err)
+					err = errors.Wrapf(err, "%d journaled mutations from %s are dropped", n, batch[0].Key)
+					atomic.AddInt64(&j.failed, int64(n))
+					j.applied(batch[:n])
+					batch = batch[n:]
+					retries = 0
+				} else {
+					log.Warnf("[Journal]\tfailed to apply %s, retrying\t%+v", batch[0].Key, err)
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case ech <- err:
+				default:
+				}
+				if retries == 0 {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(j.retry):
+				}
+			}
+		}
+	}))
+	return ech
+}
+
+// applied forgets the applied mutations, the journal is truncated once nothing is pending.
+func (j *journal) applied(es []*journalEntry) {
+	if len(es) == 0 {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// the slots are released last, so Pending is 0 only after the journal is truncated
+	defer func() {
+		for range es {
+			<-j.slots
+		}
+	}()
+
+	now := time.Now()
+	for _, e := range es {
+		if j.pending[e.Key] == e {
+			delete(j.pending, e.Key)
+		}
+	}
+	atomic.StoreInt64(&j.lag, int64(now.Sub(es[len(es)-1].at)))
+
+	if len(j.pending) == 0 && j.file != nil {
+		atomic.StoreInt64(&j.lag, 0)
+		if err := j.file.Truncate(0); err != nil {
+			log.Warnf("[Journal]\tfailed to truncate journal\t%+v", err)
+			return
+		}
+		j.offset = 0
+	}
+}
+
+func (j *journal) Pending() int {
+	return len(j.slots)
+}
+
+func (j *journal) Lag() time.Duration {
+	return time.Duration(atomic.LoadInt64(&j.lag))
+}
+
+func (j *journal) Failed() int64 {
+	return atomic.LoadInt64(&j.failed)
+}
+
+func (j *journal) Close() error {
+	j.mu.Lock()
+	if j.file != nil {
+		if err := j.file.Close(); err != nil {
+			log.Warnf("[Journal]\tfailed to close journal\t%+v", err)
+		}
+		j.file = nil
+	}
+	j.mu.Unlock()
+
+	return j.HaloDB.Close()
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/timeutil"
+)
+
+type JournalOption func(*journal) error
+
+var (
+	defaultJournalOpts = []JournalOption{
+		WithJournalPath(".halodb.journal"),
+		WithJournalQueueSize(10000),
+		WithJournalRetryDuration("1s"),
+		WithJournalMaxRetries(10),
+		WithJournalErrGroup(errgroup.Get()),
+	}
+)
+
+func WithJournalPath(path string) JournalOption {
+	return func(j *journal) error {
+		if len(path) == 0 {
+			return nil
+		}
+		j.path = path
+		return nil
+	}
+}
+
+func WithJournalQueueSize(size int) JournalOption {
+	return func(j *journal) error {
+		if size <= 0 {
+			return nil
+		}
+		j.size = size
+		return nil
+	}
+}
+
+func WithJournalRetryDuration(dur string) JournalOption {
+	return func(j *journal) error {
+		if len(dur) == 0 {
+			return nil
+		}
+		d, err := timeutil.Parse(dur)
+		if err != nil {
+			return err
+		}
+		j.retry = d
+		return nil
+	}
+}
+
+// WithJournalMaxRetries sets how many times a failed mutation is retried before it is dropped.
+func WithJournalMaxRetries(n int) JournalOption {
+	return func(j *journal) error {
+		if n <= 0 {
+			return nil
+		}
+		j.maxRetries = n
+		return nil
+	}
+}
+
+func WithJournalErrGroup(eg errgroup.Group) JournalOption {
+	return func(j *journal) error {
+		if eg != nil {
+			j.eg = eg
+		}
+		return nil
+	}
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+// failDB fails the writes of the keys in fails.
+type failDB struct {
+	*memDB
+	fails map[string]bool
+}
+
+func (d *failDB) Put(key, value string) error {
+	if d.fails[key] {
+		return errors.Errorf("failed to put %s", key)
+	}
+	return d.memDB.Put(key, value)
+}
+
+func Test_journal_replay(t *testing.T) {
+	type args struct {
+		ms []Mutation
+		// tail is appended to the journal after the crash
+		tail []byte
+		// tear is the number of bytes cut from the tail of the journal after the crash
+		tear int64
+	}
+	type want struct {
+		kvs map[string]string
+	}
+	type test struct {
+		name      string
+		args      args
+		kvs       map[string]string
+		want      want
+		checkFunc func(want, map[string]string) error
+	}
+	defaultCheckFunc := func(w want, kvs map[string]string) error {
+		if !reflect.DeepEqual(kvs, w.kvs) {
+			return errors.Errorf("got entries = %v, want %v", kvs, w.kvs)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the mutations are replayed in order",
+			args: args{
+				ms: []Mutation{
+					{Key: "a", Value: "2"},
+					{Key: "b", Delete: true},
+					{Key: "a", Value: "3"},
+				},
+			},
+			kvs: map[string]string{
+				"a": "1",
+				"b": "1",
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "3",
+				},
+			},
+		},
+		{
+			name: "a torn record at the tail is dropped",
+			args: args{
+				ms: []Mutation{
+					{Key: "a", Value: "2"},
+					{Key: "b", Value: "2"},
+				},
+				tear: 2,
+			},
+			kvs: map[string]string{},
+			want: want{
+				kvs: map[string]string{
+					"a": "2",
+				},
+			},
+		},
+		{
+			name: "a record with a wrong checksum is dropped",
+			args: args{
+				ms: []Mutation{
+					{Key: "a", Value: "2"},
+				},
+				tail: append(appendJournalMutation(nil, Mutation{Key: "b", Value: "2"}), 0, 0, 0, 0),
+			},
+			kvs: map[string]string{},
+			want: want{
+				kvs: map[string]string{
+					"a": "2",
+				},
+			},
+		},
+		{
+			name: "an empty journal replays nothing",
+			kvs: map[string]string{
+				"a": "1",
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "1",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			path := filepath.Join(tt.TempDir(), "journal")
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+
+			j, err := NewJournal(newMemDB(nil), WithJournalPath(path))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err = j.Open(""); err != nil {
+				tt.Fatal(err)
+			}
+			for i, err := range j.(Batcher).Write(test.args.ms) {
+				if err != nil {
+					tt.Fatalf("failed to write mutation %d: %v", i, err)
+				}
+			}
+			if err = j.(*journal).file.Close(); err != nil {
+				tt.Fatal(err)
+			}
+			fi, err := os.Stat(path)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err = os.Truncate(path, fi.Size()-test.args.tear); err != nil {
+				tt.Fatal(err)
+			}
+			if len(test.args.tail) != 0 {
+				f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+				if err != nil {
+					tt.Fatal(err)
+				}
+				_, err = f.Write(test.args.tail)
+				f.Close()
+				if err != nil {
+					tt.Fatal(err)
+				}
+			}
+
+			db := newMemDB(test.kvs)
+			j, err = NewJournal(db, WithJournalPath(path))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err = j.Open(""); err != nil {
+				tt.Fatal(err)
+			}
+			defer j.Close()
+			if err := checkFunc(test.want, db.entries()); err != nil {
+				tt.Error(err)
+			}
+			if fi, err := os.Stat(path); err != nil || fi.Size() != 0 {
+				tt.Errorf("journal is not truncated after the replay: %v", err)
+			}
+		})
+	}
+}
+
+func Test_journal_Start(t *testing.T) {
+	type args struct {
+		// commits are committed as transaction records before ms are written
+		commits [][]Mutation
+		ms      []Mutation
+	}
+	type fields struct {
+		fails      map[string]bool
+		maxRetries int
+	}
+	type want struct {
+		kvs    map[string]string
+		failed int64
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, map[string]string, Journal) error
+	}
+	defaultCheckFunc := func(w want, kvs map[string]string, j Journal) error {
+		if !reflect.DeepEqual(kvs, w.kvs) {
+			return errors.Errorf("got entries = %v, want %v", kvs, w.kvs)
+		}
+		if got := j.Failed(); got != w.failed {
+			return errors.Errorf("got failed = %d, want %d", got, w.failed)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the applied mutations truncate the journal",
+			args: args{
+				ms: []Mutation{
+					{Key: "a", Value: "1"},
+					{Key: "b", Value: "1"},
+					{Key: "a", Delete: true},
+				},
+			},
+			want: want{
+				kvs: map[string]string{
+					"b": "1",
+				},
+			},
+		},
+		{
+			name: "a mutation failing more than the max retries is dropped",
+			args: args{
+				ms: []Mutation{
+					{Key: "a", Value: "1"},
+					{Key: "b", Value: "1"},
+					{Key: "c", Value: "1"},
+				},
+			},
+			fields: fields{
+				fails: map[string]bool{
+					"b": true,
+				},
+				maxRetries: 2,
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "1",
+					"c": "1",
+				},
+				failed: 1,
+			},
+		},
+		{
+			name: "a failing mutation of a transaction is dropped with the rest of its record",
+			args: args{
+				commits: [][]Mutation{
+					{
+						{Key: "a", Value: "1"},
+						{Key: "b", Value: "1"},
+						{Key: "c", Value: "1"},
+					},
+				},
+				ms: []Mutation{
+					{Key: "d", Value: "1"},
+				},
+			},
+			fields: fields{
+				fails: map[string]bool{
+					"b": true,
+				},
+				maxRetries: 2,
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "1",
+					"d": "1",
+				},
+				failed: 2,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			path := filepath.Join(tt.TempDir(), "journal")
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+
+			db := &failDB{
+				memDB: newMemDB(nil),
+				fails: test.fields.fails,
+			}
+			j, err := NewJournal(db,
+				WithJournalPath(path),
+				WithJournalRetryDuration("1ms"),
+				WithJournalMaxRetries(test.fields.maxRetries),
+			)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err = j.Open(""); err != nil {
+				tt.Fatal(err)
+			}
+			defer j.Close()
+			ech := j.Start(ctx)
+			go func() {
+				for range ech {
+				}
+			}()
+			for i, ms := range test.args.commits {
+				if err := j.(Transactor).Commit(ms); err != nil {
+					tt.Fatalf("failed to commit transaction %d: %v", i, err)
+				}
+			}
+			for i, err := range j.(Batcher).Write(test.args.ms) {
+				if err != nil {
+					tt.Fatalf("failed to write mutation %d: %v", i, err)
+				}
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for j.Pending() > 0 {
+				if time.Now().After(deadline) {
+					tt.Fatalf("%d mutations are still pending", j.Pending())
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			if err := checkFunc(test.want, db.entries(), j); err != nil {
+				tt.Error(err)
+			}
+			if fi, err := os.Stat(path); err != nil || fi.Size() != 0 {
+				tt.Errorf("journal is not truncated once applied: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+)
+
+func TestMain(m *testing.M) {
+	log.Init()
+	os.Exit(m.Run())
+}
+
+// memDB is an in-memory HaloDB.
+type memDB struct {
+	mu sync.Mutex
+	m  map[string]string
+}
+
+func newMemDB(kvs map[string]string) *memDB {
+	m := make(map[string]string, len(kvs))
+	for k, v := range kvs {
+		m[k] = v
+	}
+	return &memDB{
+		m: m,
+	}
+}
+
+func (d *memDB) Open(path string) error {
+	return nil
+}
+
+func (d *memDB) Put(key, value string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.m[key] = value
+	return nil
+}
+
+func (d *memDB) Get(key string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	v, ok := d.m[key]
+	if !ok {
+		return "", errNotFound(key)
+	}
+	return v, nil
+}
+
+func (d *memDB) Delete(key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.m[key]; !ok {
+		return errNotFound(key)
+	}
+	delete(d.m, key)
+	return nil
+}
+
+func (d *memDB) Size() (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return int64(len(d.m)), nil
+}
+
+func (d *memDB) Close() error {
+	return nil
+}
+
+// entries returns a copy of the entries.
+func (d *memDB) entries() map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	m := make(map[string]string, len(d.m))
+	for k, v := range d.m {
+		m[k] = v
+	}
+	return m
+}
+
+// slowDB delays the reads and counts them.
+type slowDB struct {
+	*memDB
+	delay time.Duration
+	gets  uint64
+}
+
+func (d *slowDB) Get(key string) (string, error) {
+	atomic.AddUint64(&d.gets, 1)
+	time.Sleep(d.delay)
+	return d.memDB.Get(key)
+}
+
+// checkReadAfterWrite writes increasing numbers to the keys while reading them concurrently,
+// and returns an error when a read returns a number older than the last write finished before it started,
+// or when a read returns a number older than the previous read of the same reader.
+func checkReadAfterWrite(h HaloDB, keys []string, writes, readers int) error {
+	written := make([]int64, len(keys))
+	var wg sync.WaitGroup
+	errs := make(chan error, len(keys)*(readers+1))
+	done := make(chan struct{})
+	for i, key := range keys {
+		i, key := i, key
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := int64(1); n <= int64(writes); n++ {
+				if err := h.Put(key, strconv.FormatInt(n, 10)); err != nil {
+					errs <- err
+					return
+				}
+				atomic.StoreInt64(&written[i], n)
+			}
+		}()
+	}
+	var rwg sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		rwg.Add(1)
+		go func() {
+			defer rwg.Done()
+			last := make([]int64, len(keys))
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				for i, key := range keys {
+					w := atomic.LoadInt64(&written[i])
+					raw, err := h.Get(key)
+					if err != nil {
+						if w > 0 {
+							errs <- errors.Errorf("%s not found after %d was written", key, w)
+							return
+						}
+						continue
+					}
+					n, err := strconv.ParseInt(raw, 10, 64)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if n < w || n < last[i] {
+						errs <- errors.Errorf("read %d of %s after %d was written and %d was read", n, key, w, last[i])
+						return
+					}
+					last[i] = n
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	rwg.Wait()
+	close(errs)
+	for err := range errs {
+		return err
+	}
+	for _, key := range keys {
+		raw, err := h.Get(key)
+		if err != nil || raw != strconv.Itoa(writes) {
+			return errors.Errorf("%s is %s after the writes, want %d: %v", key, raw, writes, err)
+		}
+	}
+	return nil
+}
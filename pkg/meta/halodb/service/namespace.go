@@ -0,0 +1,135 @@
+package service
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+type namespace struct {
+	HaloDB
+	name string
+	// mu guards prefix, which changes when the namespace is dropped
+	mu     sync.RWMutex
+	prefix string
+}
+
+// Namespace is a HaloDB whose keys are prefixed with the namespace name and generation.
+// Dropping the namespace moves it to the next generation, the entries of the previous
+// generations become unreachable but stay on disk because libhalodb cannot list keys.
+type Namespace interface {
+	HaloDB
+	// PutWithTTL stores the entries which expire together after ttl, the underlying HaloDB must be a TTL.
+	PutWithTTL(d time.Duration, kvs map[string]string) error
+	Name() string
+	Drop() error
+	// Reset forgets the generation, so it is read again from a truncated HaloDB.
+	Reset()
+}
+
+func NewNamespace(h HaloDB, name string) (Namespace, error) {
+	if len(name) == 0 {
+		return nil, errors.New("empty namespace name")
+	}
+	return &namespace{
+		HaloDB: h,
+		name:   name,
+	}, nil
+}
+
+func (n *namespace) Name() string {
+	return n.name
+}
+
+// generationKey is not prefixed, so it survives the drops.
+func (n *namespace) generationKey() string {
+	return "nsgen:" + strconv.Itoa(len(n.name)) + ":" + n.name
+}
+
+func (n *namespace) generation() uint64 {
+	raw, err := n.HaloDB.Get(n.generationKey())
+	if err != nil {
+		return 0
+	}
+	gen, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return gen
+}
+
+func (n *namespace) prefixOf(gen uint64) string {
+	return "ns:" + strconv.Itoa(len(n.name)) + ":" + n.name + ":" + strconv.FormatUint(gen, 10) + ":"
+}
+
+// key returns the prefixed key, the generation is loaded on the first call because HaloDB is opened after the construction.
+func (n *namespace) key(key string) string {
+	n.mu.RLock()
+	prefix := n.prefix
+	n.mu.RUnlock()
+	if len(prefix) != 0 {
+		return prefix + key
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.prefix) == 0 {
+		n.prefix = n.prefixOf(n.generation())
+	}
+	return n.prefix + key
+}
+
+func (n *namespace) Put(key, value string) error {
+	return n.HaloDB.Put(n.key(key), value)
+}
+
+func (n *namespace) PutWithTTL(d time.Duration, kvs map[string]string) error {
+	t, ok := n.HaloDB.(TTL)
+	if !ok {
+		return errors.New("ttl is not enabled")
+	}
+	pkvs := make(map[string]string, len(kvs))
+	for key, value := range kvs {
+		pkvs[n.key(key)] = value
+	}
+	return t.PutWithTTL(d, pkvs)
+}
+
+func (n *namespace) Get(key string) (string, error) {
+	return n.HaloDB.Get(n.key(key))
+}
+
+func (n *namespace) Delete(key string) error {
+	return n.HaloDB.Delete(n.key(key))
+}
+
+func (n *namespace) Drop() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	gen := n.generation() + 1
+	err := n.HaloDB.Put(n.generationKey(), strconv.FormatUint(gen, 10))
+	if err != nil {
+		return errors.Wrapf(err, "failed to drop namespace %s", n.name)
+	}
+	n.prefix = n.prefixOf(gen)
+	return nil
+}
+
+func (n *namespace) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.prefix = ""
+}
+
+func (n *namespace) Commit(ms []Mutation) error {
+	pms := make([]Mutation, 0, len(ms))
+	for _, m := range ms {
+		m.Key = n.key(m.Key)
+		pms = append(pms, m)
+	}
+	return Commit(n.HaloDB, pms)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/safety"
+)
+
+type registry struct {
+	tenants map[string]Tenant
+	names   []string
+	dur     time.Duration
+	eg      errgroup.Group
+}
+
+// Registry manages the HaloDB of each tenant.
+// It closes the idle tenants and follows their compaction schedules.
+type Registry interface {
+	Tenant(name string) (Tenant, bool)
+	Tenants() []Tenant
+	Start(ctx context.Context) <-chan error
+	Close() error
+}
+
+func NewRegistry(opts ...RegistryOption) (Registry, error) {
+	r := &registry{
+		tenants: make(map[string]Tenant),
+	}
+
+	for _, opt := range append(defaultRegistryOpts, opts...) {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (r *registry) Tenant(name string) (Tenant, bool) {
+	t, ok := r.tenants[name]
+	return t, ok
+}
+
+func (r *registry) Tenants() []Tenant {
+	ts := make([]Tenant, 0, len(r.names))
+	for _, name := range r.names {
+		ts = append(ts, r.tenants[name])
+	}
+	return ts
+}
+
+func (r *registry) Start(ctx context.Context) <-chan error {
+	ech := make(chan error, len(r.names)+1)
+	r.eg.Go(safety.RecoverFunc(func() error {
+		defer close(ech)
+		tick := time.NewTicker(r.dur)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case now := <-tick.C:
+				for _, t := range r.Tenants() {
+					err := t.Maintain(now)
+					if err != nil {
+						select {
+						case <-ctx.Done():
+							return ctx.Err()
+						case ech <- err:
+						}
+					}
+				}
+			}
+		}
+	}))
+	return ech
+}
+
+func (r *registry) Close() (errs error) {
+	for _, t := range r.Tenants() {
+		err := t.Close()
+		if err != nil {
+			errs = errors.Wrap(errs, err.Error())
+		}
+	}
+	return errs
+}
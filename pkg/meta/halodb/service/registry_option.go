@@ -0,0 +1,52 @@
+package service
+
+import (
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/timeutil"
+)
+
+type RegistryOption func(*registry) error
+
+var (
+	defaultRegistryOpts = []RegistryOption{
+		WithMaintenanceDuration("1m"),
+		WithRegistryErrGroup(errgroup.Get()),
+	}
+)
+
+func WithTenants(ts ...Tenant) RegistryOption {
+	return func(r *registry) error {
+		for _, t := range ts {
+			if _, ok := r.tenants[t.Name()]; ok {
+				return errors.Errorf("duplicated tenant %s", t.Name())
+			}
+			r.tenants[t.Name()] = t
+			r.names = append(r.names, t.Name())
+		}
+		return nil
+	}
+}
+
+func WithMaintenanceDuration(dur string) RegistryOption {
+	return func(r *registry) error {
+		if len(dur) == 0 {
+			return nil
+		}
+		d, err := timeutil.Parse(dur)
+		if err != nil {
+			return err
+		}
+		r.dur = d
+		return nil
+	}
+}
+
+func WithRegistryErrGroup(eg errgroup.Group) RegistryOption {
+	return func(r *registry) error {
+		if eg != nil {
+			r.eg = eg
+		}
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+const defaultVirtualNodes = 128
+
+type ring struct {
+	hashes []uint64
+	owners map[uint64]string
+}
+
+// Ring is a consistent hash ring of storage node addresses.
+type Ring interface {
+	Owner(key string) string
+}
+
+func NewRing(peers []string, vnodes int) (Ring, error) {
+	if len(peers) == 0 {
+		return nil, errors.New("no peers for hash ring")
+	}
+	if vnodes <= 0 {
+		vnodes = defaultVirtualNodes
+	}
+
+	r := &ring{
+		hashes: make([]uint64, 0, len(peers)*vnodes),
+		owners: make(map[uint64]string, len(peers)*vnodes),
+	}
+	for _, peer := range peers {
+		for i := 0; i < vnodes; i++ {
+			h := hash(peer + "#" + strconv.Itoa(i))
+			if _, ok := r.owners[h]; ok {
+				continue
+			}
+			r.owners[h] = peer
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool {
+		return r.hashes[i] < r.hashes[j]
+	})
+
+	return r, nil
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func (r *ring) Owner(key string) string {
+	h := hash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= h
+	})
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+)
+
+type tenant struct {
+	name string
+	path string
+	idle time.Duration
+	// compaction only runs for window at the start of every interval when interval is set
+	interval time.Duration
+	window   time.Duration
+	// mu is held by the operations and exclusively while opening or closing db
+	mu        sync.RWMutex
+	db        HaloDB
+	paused    bool
+	lastUsed  int64
+	newHaloDB func() (HaloDB, error)
+}
+
+// Tenant is a HaloDB with its own data directory, opened on the first use and closed when idle.
+type Tenant interface {
+	HaloDB
+	Batcher
+	Name() string
+	// Opened reports whether the data directory is opened.
+	Opened() bool
+	// Maintain closes the idle HaloDB and follows the compaction schedule.
+	Maintain(now time.Time) error
+	// Truncate closes the HaloDB and removes the data directory, the next operation opens it empty.
+	Truncate() error
+}
+
+func NewTenant(name string, opts ...TenantOption) (Tenant, error) {
+	t := &tenant{
+		name:      name,
+		newHaloDB: New,
+	}
+
+	for _, opt := range append(defaultTenantOpts, opts...) {
+		if err := opt(t); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(t.name) == 0 {
+		return nil, errors.New("empty tenant name")
+	}
+	if len(t.path) == 0 {
+		return nil, errors.Errorf("no data directory for tenant %s", t.name)
+	}
+
+	return t, nil
+}
+
+func (t *tenant) Name() string {
+	return t.name
+}
+
+func (t *tenant) Opened() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.db != nil
+}
+
+// acquire returns the opened HaloDB, release must be called after the operation.
+func (t *tenant) acquire() (db HaloDB, release func(), err error) {
+	for {
+		t.mu.RLock()
+		if t.db != nil {
+			atomic.StoreInt64(&t.lastUsed, time.Now().UnixNano())
+			return t.db, t.mu.RUnlock, nil
+		}
+		t.mu.RUnlock()
+
+		err = t.open()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func (t *tenant) open() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.db != nil {
+		return nil
+	}
+	db, err := t.newHaloDB()
+	if err != nil {
+		return err
+	}
+	err = db.Open(t.path)
+	if err != nil {
+		if cerr := db.Close(); cerr != nil {
+			log.Warnf("[Tenant]\tfailed to release tenant %s\t%+v", t.name, cerr)
+		}
+		return errors.Wrapf(err, "failed to open tenant %s", t.name)
+	}
+	t.db = db
+	t.paused = false
+	atomic.StoreInt64(&t.lastUsed, time.Now().UnixNano())
+	log.Infof("[Tenant]\ttenant %s opened", t.name)
+
+	// the tenant is usable, Maintain retries the compaction schedule
+	if err = t.schedule(time.Now()); err != nil {
+		log.Warnf("[Tenant]\t%+v", err)
+	}
+	return nil
+}
+
+// Open opens the data directory of the tenant, path overrides the configured one when it is not empty.
+func (t *tenant) Open(path string) error {
+	if len(path) != 0 {
+		t.mu.Lock()
+		t.path = path
+		t.mu.Unlock()
+	}
+	return t.open()
+}
+
+func (t *tenant) Put(key, value string) error {
+	db, release, err := t.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return db.Put(key, value)
+}
+
+func (t *tenant) Write(ms []Mutation) []error {
+	db, release, err := t.acquire()
+	if err != nil {
+		errs := make([]error, len(ms))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	defer release()
+
+	if b, ok := db.(Batcher); ok {
+		return b.Write(ms)
+	}
+	errs := make([]error, len(ms))
+	for i, m := range ms {
+		if m.Delete {
+			errs[i] = db.Delete(m.Key)
+		} else {
+			errs[i] = db.Put(m.Key, m.Value)
+		}
+	}
+	return errs
+}
+
+func (t *tenant) Commit(ms []Mutation) error {
+	db, release, err := t.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return Commit(db, ms)
+}
+
+func (t *tenant) Get(key string) (string, error) {
+	db, release, err := t.acquire()
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	return db.Get(key)
+}
+
+func (t *tenant) Delete(key string) error {
+	db, release, err := t.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return db.Delete(key)
+}
+
+// Size returns 0 without opening the data directory when the tenant is closed.
+func (t *tenant) Size() (int64, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.db == nil {
+		return 0, nil
+	}
+	return t.db.Size()
+}
+
+func (t *tenant) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.close()
+}
+
+// close must be called with t.mu held.
+func (t *tenant) close() error {
+	if t.db == nil {
+		return nil
+	}
+	err := t.db.Close()
+	t.db = nil
+	if err != nil {
+		return errors.Wrapf(err, "failed to close tenant %s", t.name)
+	}
+	log.Infof("[Tenant]\ttenant %s closed", t.name)
+	return nil
+}
+
+func (t *tenant) Truncate() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	err := t.close()
+	if err != nil {
+		return err
+	}
+	err = os.RemoveAll(t.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove the data directory of tenant %s", t.name)
+	}
+	log.Infof("[Tenant]\ttenant %s truncated", t.name)
+	return nil
+}
+
+func (t *tenant) Maintain(now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.db == nil {
+		return nil
+	}
+	if t.idle > 0 && now.Sub(time.Unix(0, atomic.LoadInt64(&t.lastUsed))) > t.idle {
+		return t.close()
+	}
+	return t.schedule(now)
+}
+
+// schedule must be called with t.mu held.
+func (t *tenant) schedule(now time.Time) error {
+	if t.interval <= 0 {
+		return nil
+	}
+	c, ok := t.db.(Compactor)
+	if !ok {
+		return nil
+	}
+	pause := time.Duration(now.UnixNano())%t.interval >= t.window
+	if pause == t.paused {
+		return nil
+	}
+	var err error
+	if pause {
+		err = c.PauseCompaction()
+	} else {
+		err = c.ResumeCompaction()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to change the compaction of tenant %s", t.name)
+	}
+	t.paused = pause
+	return nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"github.com/rinx/vald-meta-halodb/internal/timeutil"
+)
+
+type TenantOption func(*tenant) error
+
+var (
+	defaultTenantOpts = []TenantOption{
+		WithTenantIdleTimeout("30m"),
+	}
+)
+
+func WithTenantPath(path string) TenantOption {
+	return func(t *tenant) error {
+		if len(path) == 0 {
+			return nil
+		}
+		t.path = path
+		return nil
+	}
+}
+
+func WithTenantIdleTimeout(dur string) TenantOption {
+	return func(t *tenant) error {
+		if len(dur) == 0 {
+			return nil
+		}
+		d, err := timeutil.Parse(dur)
+		if err != nil {
+			return err
+		}
+		t.idle = d
+		return nil
+	}
+}
+
+// WithCompactionSchedule lets the compaction run only for window at the start of every interval.
+// The intervals are aligned to the unix epoch, so "24h" and "2h" allow it from 00:00 to 02:00 UTC.
+func WithCompactionSchedule(interval, window string) TenantOption {
+	return func(t *tenant) error {
+		if len(interval) == 0 {
+			return nil
+		}
+		i, err := timeutil.Parse(interval)
+		if err != nil {
+			return err
+		}
+		w, err := timeutil.Parse(window)
+		if err != nil {
+			return err
+		}
+		t.interval = i
+		t.window = w
+		return nil
+	}
+}
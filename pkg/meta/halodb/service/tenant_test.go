@@ -0,0 +1,318 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+// compactDB is a memDB which records its opens, closes and compaction changes.
+type compactDB struct {
+	*memDB
+	mu    sync.Mutex
+	paths []string
+	calls []string
+}
+
+func (d *compactDB) Open(path string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paths = append(d.paths, path)
+	return nil
+}
+
+func (d *compactDB) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, "close")
+	return nil
+}
+
+func (d *compactDB) PauseCompaction() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, "pause")
+	return nil
+}
+
+func (d *compactDB) ResumeCompaction() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, "resume")
+	return nil
+}
+
+func Test_tenant_Maintain(t *testing.T) {
+	// step reads the tenant when get is set, then maintains it at the time
+	type step struct {
+		get bool
+		at  func() time.Time
+	}
+	type args struct {
+		steps []step
+	}
+	type fields struct {
+		opts []TenantOption
+	}
+	type want struct {
+		opened bool
+		opens  int
+		// calls are the closes and compaction changes after the first step
+		calls []string
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, Tenant, int, []string) error
+	}
+	defaultCheckFunc := func(w want, tn Tenant, opens int, calls []string) error {
+		if tn.Opened() != w.opened {
+			return errors.Errorf("got opened = %v, want %v", tn.Opened(), w.opened)
+		}
+		if opens != w.opens {
+			return errors.Errorf("got opens = %d, want %d", opens, w.opens)
+		}
+		if !reflect.DeepEqual(calls, w.calls) {
+			return errors.Errorf("got calls = %v, want %v", calls, w.calls)
+		}
+		return nil
+	}
+	after := func(d time.Duration) func() time.Time {
+		return func() time.Time {
+			return time.Now().Add(d)
+		}
+	}
+	utc := func(day, hour, min int) func() time.Time {
+		return func() time.Time {
+			return time.Date(2020, time.January, day, hour, min, 0, 0, time.UTC)
+		}
+	}
+	tests := []test{
+		{
+			name: "a tenant is not opened before its first use",
+			args: args{
+				steps: []step{
+					{at: after(0)},
+				},
+			},
+			want: want{
+				opened: false,
+			},
+		},
+		{
+			name: "a tenant is opened by its first use",
+			args: args{
+				steps: []step{
+					{get: true, at: after(0)},
+					{get: true, at: after(0)},
+				},
+			},
+			want: want{
+				opened: true,
+				opens:  1,
+			},
+		},
+		{
+			name: "an idle tenant is closed and opened again by the next use",
+			args: args{
+				steps: []step{
+					{get: true, at: after(0)},
+					{at: after(time.Hour)},
+					{get: true, at: after(0)},
+				},
+			},
+			fields: fields{
+				opts: []TenantOption{
+					WithTenantIdleTimeout("30m"),
+				},
+			},
+			want: want{
+				opened: true,
+				opens:  2,
+				calls:  []string{"close"},
+			},
+		},
+		{
+			name: "a tenant used within the idle timeout is kept opened",
+			args: args{
+				steps: []step{
+					{get: true, at: after(0)},
+					{at: after(10 * time.Minute)},
+				},
+			},
+			fields: fields{
+				opts: []TenantOption{
+					WithTenantIdleTimeout("30m"),
+				},
+			},
+			want: want{
+				opened: true,
+				opens:  1,
+			},
+		},
+		{
+			name: "the compaction only runs in the window aligned to UTC",
+			args: args{
+				steps: []step{
+					{get: true, at: utc(1, 3, 0)},
+					{at: utc(1, 12, 0)},
+					{at: utc(2, 0, 0)},
+					{at: utc(2, 1, 59)},
+					{at: utc(2, 2, 0)},
+				},
+			},
+			fields: fields{
+				opts: []TenantOption{
+					WithTenantIdleTimeout("0s"),
+					WithCompactionSchedule("24h", "2h"),
+				},
+			},
+			want: want{
+				opened: true,
+				opens:  1,
+				calls:  []string{"resume", "pause"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := &compactDB{
+				memDB: newMemDB(nil),
+			}
+			tn, err := NewTenant("t", append([]TenantOption{WithTenantPath(tt.TempDir())}, test.fields.opts...)...)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			opens := 0
+			tn.(*tenant).newHaloDB = func() (HaloDB, error) {
+				opens++
+				return db, nil
+			}
+
+			var mark int
+			for i, s := range test.args.steps {
+				if s.get {
+					// the entry is missing, only the opening matters
+					_, _ = tn.Get("key")
+				}
+				if err := tn.Maintain(s.at()); err != nil {
+					tt.Fatal(err)
+				}
+				if i == 0 {
+					// the schedule checked at the opening depends on the current time
+					db.mu.Lock()
+					mark = len(db.calls)
+					db.mu.Unlock()
+				}
+			}
+			db.mu.Lock()
+			calls := append([]string(nil), db.calls[mark:]...)
+			db.mu.Unlock()
+			if len(calls) == 0 {
+				calls = nil
+			}
+			if err := checkFunc(test.want, tn, opens, calls); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_tenant_Truncate(t *testing.T) {
+	type args struct {
+		// reopen reads the tenant after the truncation
+		reopen bool
+	}
+	type want struct {
+		opened bool
+		opens  int
+	}
+	type test struct {
+		name      string
+		args      args
+		want      want
+		checkFunc func(want, Tenant, int, string) error
+	}
+	defaultCheckFunc := func(w want, tn Tenant, opens int, path string) error {
+		if tn.Opened() != w.opened {
+			return errors.Errorf("got opened = %v, want %v", tn.Opened(), w.opened)
+		}
+		if opens != w.opens {
+			return errors.Errorf("got opens = %d, want %d", opens, w.opens)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			return errors.Errorf("got data directory %s = %v, want removed", path, err)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "a truncated tenant is closed and its data directory is removed",
+			want: want{
+				opened: false,
+				opens:  1,
+			},
+		},
+		{
+			name: "a truncated tenant is opened again by the next use",
+			args: args{
+				reopen: true,
+			},
+			want: want{
+				opened: true,
+				opens:  2,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			path := filepath.Join(tt.TempDir(), "t")
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				tt.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(path, "1.data"), []byte("data"), 0o644); err != nil {
+				tt.Fatal(err)
+			}
+			tn, err := NewTenant("t", WithTenantPath(path))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			opens := 0
+			tn.(*tenant).newHaloDB = func() (HaloDB, error) {
+				opens++
+				return &compactDB{
+					memDB: newMemDB(nil),
+				}, nil
+			}
+			_, _ = tn.Get("key")
+
+			if err := tn.Truncate(); err != nil {
+				tt.Fatal(err)
+			}
+			if test.args.reopen {
+				_, _ = tn.Get("key")
+			}
+			if err := checkFunc(test.want, tn, opens, path); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+// ErrTransactionUnsupported is returned by Commit when no journal is below the HaloDB.
+var ErrTransactionUnsupported = errors.New("transactions require the asynchronous write journal")
+
+// Transactor applies the mutations atomically, after a crash either all of them are applied or none of them.
+// The decorators translate the mutations and pass them down, the journal makes them atomic.
+type Transactor interface {
+	Commit(ms []Mutation) error
+}
+
+// Commit passes the mutations to h when it is a Transactor.
+func Commit(h HaloDB, ms []Mutation) error {
+	t, ok := h.(Transactor)
+	if !ok {
+		return ErrTransactionUnsupported
+	}
+	return t.Commit(ms)
+}
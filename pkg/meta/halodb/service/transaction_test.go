@@ -0,0 +1,196 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+func TestCommit(t *testing.T) {
+	type args struct {
+		txs [][]Mutation
+		// tear is the number of bytes cut from the tail of the journal after the crash
+		tear int64
+	}
+	type fields struct {
+		kvs  map[string]string
+		size int
+	}
+	type want struct {
+		kvs map[string]string
+		err error
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, map[string]string, error) error
+	}
+	defaultCheckFunc := func(w want, kvs map[string]string, err error) error {
+		if (err == nil) != (w.err == nil) || (err != nil && err.Error() != w.err.Error()) {
+			return errors.Errorf("got error = %v, want %v", err, w.err)
+		}
+		if !reflect.DeepEqual(kvs, w.kvs) {
+			return errors.Errorf("got entries = %v, want %v", kvs, w.kvs)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "a transaction committed before the crash is replayed all together",
+			args: args{
+				txs: [][]Mutation{
+					{
+						{Key: "a", Value: "2"},
+						{Key: "b", Delete: true},
+						{Key: "c", Value: "3"},
+					},
+				},
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"a": "1",
+					"b": "1",
+				},
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "2",
+					"c": "3",
+				},
+			},
+		},
+		{
+			name: "a transaction torn by the crash is replayed not at all",
+			args: args{
+				txs: [][]Mutation{
+					{
+						{Key: "a", Value: "2"},
+						{Key: "b", Delete: true},
+						{Key: "c", Value: "3"},
+					},
+				},
+				tear: 3,
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"a": "1",
+					"b": "1",
+				},
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "1",
+					"b": "1",
+				},
+			},
+		},
+		{
+			name: "the transactions before a torn one are replayed",
+			args: args{
+				txs: [][]Mutation{
+					{
+						{Key: "a", Value: "2"},
+						{Key: "b", Value: "2"},
+					},
+					{
+						{Key: "a", Value: "3"},
+						{Key: "c", Value: "3"},
+					},
+				},
+				tear: 1,
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"a": "1",
+				},
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "2",
+					"b": "2",
+				},
+			},
+		},
+		{
+			name: "a transaction larger than the queue is rejected",
+			args: args{
+				txs: [][]Mutation{
+					{
+						{Key: "a", Value: "2"},
+						{Key: "b", Value: "2"},
+					},
+				},
+			},
+			fields: fields{
+				kvs: map[string]string{
+					"a": "1",
+				},
+				size: 1,
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "1",
+				},
+				err: errors.New("transaction of 2 mutations exceeds the journal queue size 1"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			path := filepath.Join(tt.TempDir(), "journal")
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+
+			// the applier is not started, so nothing reaches HaloDB before the crash
+			j, err := NewJournal(newMemDB(nil), WithJournalPath(path), WithJournalQueueSize(test.fields.size))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err = j.Open(""); err != nil {
+				tt.Fatal(err)
+			}
+			var cerr error
+			for _, ms := range test.args.txs {
+				if err := Commit(j, ms); err != nil {
+					cerr = err
+				}
+			}
+			if err = j.(*journal).file.Close(); err != nil {
+				tt.Fatal(err)
+			}
+			if test.args.tear > 0 {
+				fi, err := os.Stat(path)
+				if err != nil {
+					tt.Fatal(err)
+				}
+				if err = os.Truncate(path, fi.Size()-test.args.tear); err != nil {
+					tt.Fatal(err)
+				}
+			}
+
+			db := newMemDB(test.fields.kvs)
+			j, err = NewJournal(db, WithJournalPath(path))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err = j.Open(""); err != nil {
+				tt.Fatal(err)
+			}
+			defer j.Close()
+			if err := checkFunc(test.want, db.entries(), cerr); err != nil {
+				tt.Error(err)
+			}
+			if fi, err := os.Stat(path); err != nil || fi.Size() != 0 {
+				tt.Errorf("journal is not truncated after the replay: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
+	"github.com/rinx/vald-meta-halodb/internal/safety"
+)
+
+// ttlHeader prefixes the values stored with an expiration time.
+// The header is followed by the deadline in unix nanoseconds and ':'.
+const ttlHeader = "\x1bttl:"
+
+const (
+	// ttlBucketSize is the time span of the deadlines listed in an expiration bucket.
+	ttlBucketSize = int64(time.Minute)
+	// ttlFirstKey and ttlLastKey hold the oldest and the latest expiration buckets.
+	ttlFirstKey = "tt:first"
+	ttlLastKey  = "tt:last"
+)
+
+type ttl struct {
+	HaloDB
+	// mu is held exclusively while sweeping so no write interleaves the expiration check and the delete
+	mu sync.RWMutex
+	// qmu guards the expiration buckets and their bounds
+	qmu     sync.Mutex
+	loaded  bool
+	bounded bool
+	first   int64
+	last    int64
+	dur     time.Duration
+	eg      errgroup.Group
+	expired uint64
+}
+
+// TTL is a HaloDB which can store entries with an expiration time.
+// Expired entries are hidden from Get and deleted by the sweeper.
+// The deadlines are stored in HaloDB in buckets of one minute, so the entries written
+// before a restart are deleted too, at most one bucket after they expired.
+type TTL interface {
+	HaloDB
+	// PutWithTTL stores the entries which expire together after ttl.
+	PutWithTTL(d time.Duration, kvs map[string]string) error
+	Start(ctx context.Context) <-chan error
+	Expired() uint64
+}
+
+func NewTTL(h HaloDB, opts ...TTLOption) (TTL, error) {
+	t := &ttl{
+		HaloDB: h,
+	}
+
+	for _, opt := range append(defaultTTLOpts, opts...) {
+		if err := opt(t); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// ttKey holds the number of the slots of the bucket, and ttSlotKey the keys of each PutWithTTL.
+// HaloDB cannot list keys, so the sweeper walks the buckets between the first and the last one.
+func (t *ttl) ttKey(bucket int64) string {
+	return "tt:" + strconv.FormatInt(bucket, 10)
+}
+
+func (t *ttl) ttSlotKey(bucket, i int64) string {
+	return t.ttKey(bucket) + "#" + strconv.FormatInt(i, 10)
+}
+
+func ttlBucket(deadline int64) int64 {
+	return deadline - deadline%ttlBucketSize
+}
+
+// Open opens HaloDB and reloads the bounds of the expiration buckets.
+func (t *ttl) Open(path string) error {
+	err := t.HaloDB.Open(path)
+	if err != nil {
+		return err
+	}
+
+	t.qmu.Lock()
+	defer t.qmu.Unlock()
+	t.loaded = false
+	t.load()
+	return nil
+}
+
+// load reads the bounds of the buckets once, it must be called with t.qmu held.
+func (t *ttl) load() {
+	if t.loaded {
+		return
+	}
+	first, fok := t.bound(ttlFirstKey)
+	last, lok := t.bound(ttlLastKey)
+	t.first, t.last = first, last
+	t.bounded = fok && lok
+	t.loaded = true
+}
+
+func (t *ttl) bound(key string) (int64, bool) {
+	raw, err := t.HaloDB.Get(key)
+	if err != nil {
+		return 0, false
+	}
+	b, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return b, true
+}
+
+func (t *ttl) bucketLen(bucket int64) int64 {
+	n, ok := t.bound(t.ttKey(bucket))
+	if !ok {
+		return 0
+	}
+	return n
+}
+
+// schedule records the keys in the next slot of the bucket of the deadline.
+func (t *ttl) schedule(deadline int64, keys []string) error {
+	raw, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	t.qmu.Lock()
+	defer t.qmu.Unlock()
+	t.load()
+
+	b := ttlBucket(deadline)
+	n := t.bucketLen(b)
+	err = t.HaloDB.Put(t.ttSlotKey(b, n), string(raw))
+	if err != nil {
+		return err
+	}
+	err = t.HaloDB.Put(t.ttKey(b), strconv.FormatInt(n+1, 10))
+	if err != nil {
+		return err
+	}
+	if !t.bounded || b < t.first {
+		err = t.HaloDB.Put(ttlFirstKey, strconv.FormatInt(b, 10))
+		if err != nil {
+			return err
+		}
+		t.first = b
+	}
+	if !t.bounded || b > t.last {
+		err = t.HaloDB.Put(ttlLastKey, strconv.FormatInt(b, 10))
+		if err != nil {
+			return err
+		}
+		t.last = b
+	}
+	t.bounded = true
+	return nil
+}
+
+func (t *ttl) encode(value string, deadline int64) string {
+	return ttlHeader + strconv.FormatInt(deadline, 10) + ":" + value
+}
+
+func (t *ttl) decode(raw string) (value string, deadline int64) {
+	if !strings.HasPrefix(raw, ttlHeader) {
+		return raw, 0
+	}
+	rest := raw[len(ttlHeader):]
+	i := strings.IndexByte(rest, ':')
+	if i < 0 {
+		return raw, 0
+	}
+	deadline, err := strconv.ParseInt(rest[:i], 10, 64)
+	if err != nil {
+		return raw, 0
+	}
+	return rest[i+1:], deadline
+}
+
+func (t *ttl) isExpired(deadline int64, now time.Time) bool {
+	return deadline > 0 && deadline <= now.UnixNano()
+}
+
+func (t *ttl) Put(key, value string) error {
+	if strings.HasPrefix(value, ttlHeader) {
+		value = t.encode(value, 0)
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.HaloDB.Put(key, value)
+}
+
+func (t *ttl) PutWithTTL(d time.Duration, kvs map[string]string) error {
+	if d <= 0 {
+		return errors.Errorf("invalid ttl %s", d)
+	}
+	deadline := time.Now().Add(d).UnixNano()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, key)
+	}
+	// the deadline is recorded first, so an entry cannot outlive a crash without it
+	err := t.schedule(deadline, keys)
+	if err != nil {
+		return err
+	}
+	for key, value := range kvs {
+		err = t.HaloDB.Put(key, t.encode(value, deadline))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ttl) Get(key string) (string, error) {
+	raw, err := t.HaloDB.Get(key)
+	if err != nil {
+		return "", err
+	}
+	value, deadline := t.decode(raw)
+	if t.isExpired(deadline, time.Now()) {
+		return "", errNotFound(key)
+	}
+	return value, nil
+}
+
+func (t *ttl) Delete(key string) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.HaloDB.Delete(key)
+}
+
+func (t *ttl) Start(ctx context.Context) <-chan error {
+	ech := make(chan error, 1)
+	t.eg.Go(safety.RecoverFunc(func() error {
+		defer close(ech)
+		tick := time.NewTicker(t.dur)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-tick.C:
+				err := t.sweep(time.Now())
+				if err != nil {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case ech <- err:
+					}
+				}
+			}
+		}
+	}))
+	return ech
+}
+
+// sweep deletes the entries of the buckets which are due at now and are still expired.
+// Entries overwritten after they were scheduled are kept.
+// A bucket whose entries could not all be deleted is kept and walked again by the next sweep.
+func (t *ttl) sweep(now time.Time) (errs error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.qmu.Lock()
+	defer t.qmu.Unlock()
+	t.load()
+
+	if !t.bounded {
+		return nil
+	}
+	b := t.first
+	for ; b <= t.last && b+ttlBucketSize <= now.UnixNano(); b += ttlBucketSize {
+		var failed bool
+		n := t.bucketLen(b)
+		for i := int64(0); i < n; i++ {
+			err := t.sweepSlot(b, i, now)
+			if err != nil {
+				errs = errors.Wrap(errs, err.Error())
+				failed = true
+			}
+		}
+		if failed {
+			break
+		}
+		if n > 0 {
+			err := t.HaloDB.Delete(t.ttKey(b))
+			if err != nil {
+				errs = errors.Wrap(errs, err.Error())
+				break
+			}
+		}
+	}
+	if b == t.first {
+		return errs
+	}
+	if b > t.last {
+		t.bounded = false
+		for _, k := range []string{ttlFirstKey, ttlLastKey} {
+			if err := t.HaloDB.Delete(k); err != nil {
+				errs = errors.Wrap(errs, err.Error())
+			}
+		}
+		return errs
+	}
+	err := t.HaloDB.Put(ttlFirstKey, strconv.FormatInt(b, 10))
+	if err != nil {
+		return errors.Wrap(errs, err.Error())
+	}
+	t.first = b
+	return errs
+}
+
+// sweepSlot deletes the expired keys of the slot, then the slot.
+func (t *ttl) sweepSlot(bucket, i int64, now time.Time) error {
+	raw, err := t.HaloDB.Get(t.ttSlotKey(bucket, i))
+	if err != nil {
+		// swept by a previous sweep which failed on another slot
+		return nil
+	}
+	var keys []string
+	if err = json.Unmarshal([]byte(raw), &keys); err != nil {
+		log.Warnf("[TTL]\tinvalid expiration slot %d of bucket %d\t%+v", i, bucket, err)
+	}
+	for _, key := range keys {
+		raw, err := t.HaloDB.Get(key)
+		if err != nil {
+			continue
+		}
+		if _, deadline := t.decode(raw); !t.isExpired(deadline, now) {
+			continue
+		}
+		err = t.HaloDB.Delete(key)
+		if err != nil {
+			log.Warnf("[TTL]\tfailed to delete expired key %s\t%+v", key, err)
+			return err
+		}
+		atomic.AddUint64(&t.expired, 1)
+	}
+	return t.HaloDB.Delete(t.ttSlotKey(bucket, i))
+}
+
+func (t *ttl) Expired() uint64 {
+	return atomic.LoadUint64(&t.expired)
+}
+
+// Commit stores the entries without expiration.
+func (t *ttl) Commit(ms []Mutation) error {
+	tms := make([]Mutation, 0, len(ms))
+	for _, m := range ms {
+		if !m.Delete && strings.HasPrefix(m.Value, ttlHeader) {
+			m.Value = t.encode(m.Value, 0)
+		}
+		tms = append(tms, m)
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return Commit(t.HaloDB, tms)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/timeutil"
+)
+
+type TTLOption func(*ttl) error
+
+var (
+	defaultTTLOpts = []TTLOption{
+		WithSweepDuration("1m"),
+		WithTTLErrGroup(errgroup.Get()),
+	}
+)
+
+func WithSweepDuration(dur string) TTLOption {
+	return func(t *ttl) error {
+		if len(dur) == 0 {
+			return nil
+		}
+		d, err := timeutil.Parse(dur)
+		if err != nil {
+			return err
+		}
+		t.dur = d
+		return nil
+	}
+}
+
+func WithTTLErrGroup(eg errgroup.Group) TTLOption {
+	return func(t *ttl) error {
+		if eg != nil {
+			t.eg = eg
+		}
+		return nil
+	}
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+)
+
+func Test_ttl_sweep(t *testing.T) {
+	type put struct {
+		ttl time.Duration
+		kvs map[string]string
+	}
+	type args struct {
+		// after is how long after the writes the restarted TTL sweeps
+		after time.Duration
+	}
+	type fields struct {
+		puts []put
+		// overwrites are stored without expiration after the puts
+		overwrites map[string]string
+	}
+	type want struct {
+		kvs     map[string]string
+		expired uint64
+		// buckets reports whether expiration buckets are left
+		buckets bool
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, TTL, *memDB) error
+	}
+	defaultCheckFunc := func(w want, t TTL, db *memDB) error {
+		kvs := make(map[string]string)
+		var buckets bool
+		for k := range db.entries() {
+			if strings.HasPrefix(k, "tt:") {
+				buckets = true
+				continue
+			}
+			if v, err := t.Get(k); err == nil {
+				kvs[k] = v
+			}
+		}
+		if !reflect.DeepEqual(kvs, w.kvs) {
+			return errors.Errorf("got entries = %v, want %v", kvs, w.kvs)
+		}
+		if got := t.Expired(); got != w.expired {
+			return errors.Errorf("got expired = %d, want %d", got, w.expired)
+		}
+		if buckets != w.buckets {
+			return errors.Errorf("got buckets left = %v, want %v: %v", buckets, w.buckets, db.entries())
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "the entries written before the restart are deleted once expired",
+			args: args{
+				after: 2 * time.Minute,
+			},
+			fields: fields{
+				puts: []put{
+					{
+						ttl: time.Second,
+						kvs: map[string]string{
+							"a": "1",
+							"b": "1",
+						},
+					},
+				},
+			},
+			want: want{
+				kvs:     map[string]string{},
+				expired: 2,
+			},
+		},
+		{
+			name: "the entries which are not due are kept",
+			args: args{
+				after: 0,
+			},
+			fields: fields{
+				puts: []put{
+					{
+						ttl: time.Hour,
+						kvs: map[string]string{
+							"a": "1",
+						},
+					},
+				},
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "1",
+				},
+				buckets: true,
+			},
+		},
+		{
+			name: "the entries overwritten without expiration are kept",
+			args: args{
+				after: 2 * time.Minute,
+			},
+			fields: fields{
+				puts: []put{
+					{
+						ttl: time.Second,
+						kvs: map[string]string{
+							"a": "1",
+							"b": "1",
+						},
+					},
+				},
+				overwrites: map[string]string{
+					"a": "2",
+				},
+			},
+			want: want{
+				kvs: map[string]string{
+					"a": "2",
+				},
+				expired: 1,
+			},
+		},
+		{
+			name: "only the due buckets are swept",
+			args: args{
+				after: 2 * time.Minute,
+			},
+			fields: fields{
+				puts: []put{
+					{
+						ttl: time.Second,
+						kvs: map[string]string{
+							"a": "1",
+						},
+					},
+					{
+						ttl: time.Hour,
+						kvs: map[string]string{
+							"b": "1",
+						},
+					},
+				},
+			},
+			want: want{
+				kvs: map[string]string{
+					"b": "1",
+				},
+				expired: 1,
+				buckets: true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			db := newMemDB(nil)
+			before, err := NewTTL(db)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err = before.Open(""); err != nil {
+				tt.Fatal(err)
+			}
+			for _, p := range test.fields.puts {
+				if err = before.PutWithTTL(p.ttl, p.kvs); err != nil {
+					tt.Fatal(err)
+				}
+			}
+			for k, v := range test.fields.overwrites {
+				if err = before.Put(k, v); err != nil {
+					tt.Fatal(err)
+				}
+			}
+
+			after, err := NewTTL(db)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err = after.Open(""); err != nil {
+				tt.Fatal(err)
+			}
+			if err = after.(*ttl).sweep(time.Now().Add(test.args.after)); err != nil {
+				tt.Fatal(err)
+			}
+			if err := checkFunc(test.want, after, db); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
+
+func Test_ttl_Get(t *testing.T) {
+	type args struct {
+		key string
+		// after is how long after the writes the entry is read
+		after time.Duration
+	}
+	type fields struct {
+		ttl time.Duration
+		kvs map[string]string
+	}
+	type want struct {
+		val string
+		err error
+	}
+	type test struct {
+		name      string
+		args      args
+		fields    fields
+		want      want
+		checkFunc func(want, string, error) error
+	}
+	defaultCheckFunc := func(w want, val string, err error) error {
+		if (err == nil) != (w.err == nil) || (err != nil && err.Error() != w.err.Error()) {
+			return errors.Errorf("got error = %v, want %v", err, w.err)
+		}
+		if err != nil && !isNotFound(err) {
+			return errors.Errorf("got error = %v, want a not found error", err)
+		}
+		if val != w.val {
+			return errors.Errorf("got value = %v, want %v", val, w.val)
+		}
+		return nil
+	}
+	tests := []test{
+		{
+			name: "an entry which has not expired is returned",
+			args: args{
+				key: "a",
+			},
+			fields: fields{
+				ttl: time.Hour,
+				kvs: map[string]string{
+					"a": "1",
+				},
+			},
+			want: want{
+				val: "1",
+			},
+		},
+		{
+			name: "an expired entry is not found",
+			args: args{
+				key:   "a",
+				after: 20 * time.Millisecond,
+			},
+			fields: fields{
+				ttl: 10 * time.Millisecond,
+				kvs: map[string]string{
+					"a": "1",
+				},
+			},
+			want: want{
+				err: errNotFound("a"),
+			},
+		},
+		{
+			name: "a missing entry is not found",
+			args: args{
+				key: "b",
+			},
+			fields: fields{
+				ttl: time.Hour,
+				kvs: map[string]string{
+					"a": "1",
+				},
+			},
+			want: want{
+				err: errNotFound("b"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			checkFunc := test.checkFunc
+			if test.checkFunc == nil {
+				checkFunc = defaultCheckFunc
+			}
+			h, err := NewTTL(newMemDB(nil))
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if err = h.Open(""); err != nil {
+				tt.Fatal(err)
+			}
+			if err = h.PutWithTTL(test.fields.ttl, test.fields.kvs); err != nil {
+				tt.Fatal(err)
+			}
+			time.Sleep(test.args.after)
+
+			val, err := h.Get(test.args.key)
+			if err := checkFunc(test.want, val, err); err != nil {
+				tt.Error(err)
+			}
+		})
+	}
+}
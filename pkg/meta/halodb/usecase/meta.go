@@ -5,40 +5,230 @@ import (
 
 	iconf "github.com/rinx/vald-meta-halodb/internal/config"
 	"github.com/rinx/vald-meta-halodb/internal/errgroup"
+	"github.com/rinx/vald-meta-halodb/internal/errors"
+	"github.com/rinx/vald-meta-halodb/internal/log"
 	"github.com/rinx/vald-meta-halodb/internal/net/grpc"
 	"github.com/rinx/vald-meta-halodb/internal/net/grpc/metric"
 	"github.com/rinx/vald-meta-halodb/internal/observability"
+	"github.com/rinx/vald-meta-halodb/internal/observability/metrics"
 	"github.com/rinx/vald-meta-halodb/internal/runner"
 	"github.com/rinx/vald-meta-halodb/internal/safety"
 	"github.com/rinx/vald-meta-halodb/internal/servers/server"
 	"github.com/rinx/vald-meta-halodb/internal/servers/starter"
+	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/apis/grpc/extension"
 	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/config"
 	handler "github.com/rinx/vald-meta-halodb/pkg/meta/halodb/handler/grpc"
 	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/handler/rest"
+	cachemetrics "github.com/rinx/vald-meta-halodb/pkg/meta/halodb/observability/metrics/cache"
+	coalescemetrics "github.com/rinx/vald-meta-halodb/pkg/meta/halodb/observability/metrics/coalesce"
+	journalmetrics "github.com/rinx/vald-meta-halodb/pkg/meta/halodb/observability/metrics/journal"
+	tenantmetrics "github.com/rinx/vald-meta-halodb/pkg/meta/halodb/observability/metrics/tenant"
+	trashmetrics "github.com/rinx/vald-meta-halodb/pkg/meta/halodb/observability/metrics/trash"
+	ttlmetrics "github.com/rinx/vald-meta-halodb/pkg/meta/halodb/observability/metrics/ttl"
 	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/router"
 	"github.com/rinx/vald-meta-halodb/pkg/meta/halodb/service"
 	"github.com/vdaas/vald/apis/grpc/meta"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type run struct {
 	eg            errgroup.Group
 	cfg           *config.Data
 	h             service.HaloDB
+	ttl           service.TTL
+	committer     service.Committer
+	journal       service.Journal
+	registry      service.Registry
+	sweeper       handler.TrashSweeper
+	client        grpc.Client
 	server        starter.Server
+	health        *health.Server
 	observability observability.Observability
 }
 
 func New(cfg *config.Data) (r runner.Runner, err error) {
-	h, err := service.New()
-	if err != nil {
-		return nil, err
-	}
-	g := handler.New(handler.WithHaloDB(h))
+	var (
+		g      meta.MetaServer
+		h      service.HaloDB
+		ttl    service.TTL
+		cm     service.Committer
+		j      service.Journal
+		reg    service.Registry
+		sw     handler.TrashSweeper
+		client grpc.Client
+		mets   []metrics.Metric
+	)
 	eg := errgroup.Get()
 
+	switch cfg.Mode {
+	case config.RouterMode:
+		if cfg.Cluster == nil {
+			return nil, errors.New("cluster configuration is required in router mode")
+		}
+		ring, err := service.NewRing(cfg.Cluster.Peers, cfg.Cluster.VirtualNodes)
+		if err != nil {
+			return nil, err
+		}
+		client = grpc.New(
+			append(
+				cfg.Cluster.Client.Opts(),
+				grpc.WithAddrs(cfg.Cluster.Peers...),
+			)...,
+		)
+		g = handler.NewCluster(
+			handler.WithClient(client),
+			handler.WithRing(ring),
+		)
+	default:
+		h, err = service.New()
+		if err != nil {
+			return nil, err
+		}
+		if cfg.HaloDB.AsyncWrite.Enabled {
+			j, err = service.NewJournal(
+				h,
+				service.WithJournalPath(cfg.HaloDB.AsyncWrite.JournalPath),
+				service.WithJournalQueueSize(cfg.HaloDB.AsyncWrite.QueueSize),
+				service.WithJournalRetryDuration(cfg.HaloDB.AsyncWrite.RetryDuration),
+				service.WithJournalMaxRetries(cfg.HaloDB.AsyncWrite.MaxRetries),
+				service.WithJournalErrGroup(eg),
+			)
+			if err != nil {
+				return nil, err
+			}
+			// the journal replays the pending mutations on Open
+			h = j
+			mets = append(mets, journalmetrics.New(j))
+		}
+		db := h
+		if cfg.HaloDB.GroupCommit.Enabled {
+			cm, err = service.NewCommitter(
+				db,
+				service.WithCommitWindow(cfg.HaloDB.GroupCommit.Window),
+				service.WithCommitBatchSize(cfg.HaloDB.GroupCommit.BatchSize),
+				service.WithCommitterErrGroup(eg),
+			)
+			if err != nil {
+				return nil, err
+			}
+			db = cm
+		}
+		db, err = newEncoding(cfg.HaloDB, db)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.HaloDB.EnableCoalescing {
+			c := service.NewCoalescer(db)
+			db = c
+			mets = append(mets, coalescemetrics.New(c))
+		}
+		if cfg.HaloDB.Cache.Enabled {
+			var c service.Cache
+			c, err = service.NewCache(
+				db,
+				service.WithCacheSize(cfg.HaloDB.Cache.Size),
+				service.WithCacheExpireDuration(cfg.HaloDB.Cache.ExpireDuration),
+			)
+			if err != nil {
+				return nil, err
+			}
+			db = c
+			mets = append(mets, cachemetrics.New(c))
+		}
+		policy, err := handler.ParseUniquenessPolicy(cfg.HaloDB.Uniqueness)
+		if err != nil {
+			return nil, err
+		}
+		hopts := []handler.Option{
+			handler.WithUniquenessPolicy(policy),
+			handler.WithHistorySize(cfg.HaloDB.HistorySize),
+			handler.WithVersioning(cfg.HaloDB.Versioning),
+			handler.WithTrash(cfg.HaloDB.Trash.Enabled),
+		}
+		var ts []service.Tenant
+		for _, ns := range cfg.HaloDB.Namespaces {
+			if ns == nil {
+				continue
+			}
+			if len(ns.Path) == 0 {
+				hopts = append(hopts, handler.WithNamespace(ns.Name, ns.Quota))
+				continue
+			}
+			t, err := service.NewTenant(
+				ns.Name,
+				service.WithTenantPath(ns.Path),
+				service.WithTenantIdleTimeout(ns.IdleTimeout),
+				service.WithCompactionSchedule(ns.CompactionInterval, ns.CompactionWindow),
+			)
+			if err != nil {
+				return nil, err
+			}
+			ts = append(ts, t)
+			tdb, err := newEncoding(cfg.HaloDB, t)
+			if err != nil {
+				return nil, err
+			}
+			hopts = append(hopts, handler.WithTenantNamespace(ns.Name, ns.Quota, t, tdb))
+		}
+		if len(ts) != 0 {
+			reg, err = service.NewRegistry(
+				service.WithTenants(ts...),
+				service.WithRegistryErrGroup(eg),
+			)
+			if err != nil {
+				return nil, err
+			}
+			m, err := tenantmetrics.New(reg)
+			if err != nil {
+				return nil, err
+			}
+			mets = append(mets, m)
+		}
+		if cfg.HaloDB.JSON.Enabled {
+			hopts = append(hopts,
+				handler.WithJSONValues(true),
+				handler.WithIndexFields(cfg.HaloDB.JSON.IndexFields...),
+			)
+		}
+		if cfg.HaloDB.TTL.Enabled {
+			ttl, err = service.NewTTL(
+				db,
+				service.WithSweepDuration(cfg.HaloDB.TTL.SweepDuration),
+				service.WithTTLErrGroup(eg),
+			)
+			if err != nil {
+				return nil, err
+			}
+			hopts = append(hopts, handler.WithTTL(ttl))
+			mets = append(mets, ttlmetrics.New(ttl))
+		} else {
+			hopts = append(hopts, handler.WithHaloDB(db))
+		}
+		g = handler.New(hopts...)
+		if cfg.HaloDB.Trash.Enabled {
+			sw, err = handler.NewTrashSweeper(
+				g,
+				handler.WithTrashRetention(cfg.HaloDB.Trash.Retention),
+				handler.WithTrashSweepDuration(cfg.HaloDB.Trash.SweepDuration),
+				handler.WithTrashSweeperErrGroup(eg),
+			)
+			if err != nil {
+				return nil, err
+			}
+			mets = append(mets, trashmetrics.New(sw))
+		}
+	}
+
+	// the health service turns NOT_SERVING once the journal dropped a mutation
+	hs := health.NewServer()
 	grpcServerOptions := []server.Option{
 		server.WithGRPCRegistFunc(func(srv *grpc.Server) {
 			meta.RegisterMetaServer(srv, g)
+			healthpb.RegisterHealthServer(srv, hs)
+			if ext, ok := g.(extension.MetaExtensionServer); ok {
+				extension.RegisterMetaExtensionServer(srv, ext)
+			}
 		}),
 		server.WithGRPCOption(
 			grpc.ChainUnaryInterceptor(grpc.RecoverInterceptor()),
@@ -56,7 +246,7 @@ func New(cfg *config.Data) (r runner.Runner, err error) {
 
 	var obs observability.Observability
 	if cfg.Observability.Enabled {
-		obs, err = observability.NewWithConfig(cfg.Observability)
+		obs, err = observability.NewWithConfig(cfg.Observability, mets...)
 		if err != nil {
 			return nil, err
 		}
@@ -68,6 +258,13 @@ func New(cfg *config.Data) (r runner.Runner, err error) {
 		)
 	}
 
+	ropts := []rest.Option{
+		rest.WithMeta(g),
+	}
+	if ext, ok := g.(extension.MetaExtensionServer); ok {
+		ropts = append(ropts, rest.WithExtension(ext))
+	}
+
 	srv, err := starter.New(
 		starter.WithConfig(cfg.Server),
 		starter.WithREST(func(sc *iconf.Server) []server.Option {
@@ -77,9 +274,7 @@ func New(cfg *config.Data) (r runner.Runner, err error) {
 						router.WithTimeout(sc.HTTP.HandlerTimeout),
 						router.WithErrGroup(eg),
 						router.WithHandler(
-							rest.New(
-								rest.WithMeta(g),
-							),
+							rest.New(ropts...),
 						),
 					)),
 			}
@@ -98,15 +293,30 @@ func New(cfg *config.Data) (r runner.Runner, err error) {
 		eg:            eg,
 		cfg:           cfg,
 		h:             h,
+		ttl:           ttl,
+		committer:     cm,
+		journal:       j,
+		registry:      reg,
+		sweeper:       sw,
+		client:        client,
 		server:        srv,
+		health:        hs,
 		observability: obs,
 	}, nil
 }
 
 func (r *run) PreStart(ctx context.Context) error {
-	err := r.h.Open(".halodb")
-	if err != nil {
-		return err
+	if r.h != nil {
+		var err error
+		if r.ttl != nil {
+			// the TTL opens the HaloDB below it and reloads its expiration buckets
+			err = r.ttl.Open(r.cfg.HaloDB.Path)
+		} else {
+			err = r.h.Open(r.cfg.HaloDB.Path)
+		}
+		if err != nil {
+			return err
+		}
 	}
 	if r.observability != nil {
 		return r.observability.PreStart(ctx)
@@ -115,13 +325,35 @@ func (r *run) PreStart(ctx context.Context) error {
 }
 
 func (r *run) Start(ctx context.Context) (<-chan error, error) {
-	ech := make(chan error, 2)
-	var oech, sech <-chan error
+	ech := make(chan error, 8)
+	var oech, sech, cech, tech, gech, jech, rech, wech <-chan error
+	if r.client != nil {
+		var err error
+		cech, err = r.client.StartConnectionMonitor(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
 	r.eg.Go(safety.RecoverFunc(func() (err error) {
 		defer close(ech)
 		if r.observability != nil {
 			oech = r.observability.Start(ctx)
 		}
+		if r.ttl != nil {
+			tech = r.ttl.Start(ctx)
+		}
+		if r.committer != nil {
+			gech = r.committer.Start(ctx)
+		}
+		if r.journal != nil {
+			jech = r.journal.Start(ctx)
+		}
+		if r.registry != nil {
+			rech = r.registry.Start(ctx)
+		}
+		if r.sweeper != nil {
+			wech = r.sweeper.Start(ctx)
+		}
 		sech = r.server.ListenAndServe(ctx)
 		for {
 			select {
@@ -129,6 +361,15 @@ func (r *run) Start(ctx context.Context) (<-chan error, error) {
 				return ctx.Err()
 			case err = <-oech:
 			case err = <-sech:
+			case err = <-cech:
+			case err = <-tech:
+			case err = <-gech:
+			case err = <-jech:
+				if err != nil && r.journal.Failed() > 0 {
+					r.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+				}
+			case err = <-rech:
+			case err = <-wech:
 			}
 			if err != nil {
 				select {
@@ -150,9 +391,39 @@ func (r *run) Stop(ctx context.Context) error {
 	if r.observability != nil {
 		r.observability.Stop(ctx)
 	}
+	r.health.Shutdown()
 	return r.server.Shutdown(ctx)
 }
 
 func (r *run) PostStop(ctx context.Context) error {
+	if r.client != nil {
+		return r.client.Close()
+	}
+	if r.registry != nil {
+		if err := r.registry.Close(); err != nil {
+			log.Errorf("[Tenant]\tfailed to close tenants\t%+v", err)
+		}
+	}
 	return r.h.Close()
 }
+
+// newEncoding wraps h with the encryption and compression configured for HaloDB.
+func newEncoding(cfg *config.HaloDB, h service.HaloDB) (service.HaloDB, error) {
+	var err error
+	if cfg.Encryption.Enabled {
+		h, err = service.NewEncryptor(
+			h,
+			service.WithEncryptionKeys(cfg.Encryption.Keys),
+			service.WithKeyEncryption(cfg.Encryption.EncryptKeys),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return service.NewCompressor(
+		h,
+		service.WithCompressAlgorithm(cfg.Compression.CompressAlgorithm),
+		service.WithCompressionLevel(cfg.Compression.CompressionLevel),
+		service.WithCompressThreshold(cfg.Compression.Threshold),
+	)
+}